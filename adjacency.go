@@ -0,0 +1,177 @@
+package dag
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ToAdjacencyList returns a map from each Node ID to the IDs of its Next Nodes, in the order of Node.Next.
+// Every Node has an entry, including Nodes without any Next Nodes.
+func (g Graph) ToAdjacencyList() map[string][]string {
+	adj := make(map[string][]string, len(g))
+	for id, node := range g {
+		adj[id] = nodeIDs(node.Next)
+	}
+	return adj
+}
+
+// SuccessorIDs returns the IDs of the Next Nodes of the Node with the given ID, sorted.
+// If the Graph does not contain the Node, ErrUnknownNode is returned.
+func (g Graph) SuccessorIDs(id string) ([]string, error) {
+	n, ok := g[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownNode, id)
+	}
+	ids := nodeIDs(n.Next)
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// PredecessorIDs returns the IDs of the Nodes that have the Node with the given ID as a Next Node, sorted.
+// If the Graph does not contain the Node, ErrUnknownNode is returned.
+func (g Graph) PredecessorIDs(id string) ([]string, error) {
+	n, ok := g[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownNode, id)
+	}
+	ids := nodeIDs(g.parents()[n])
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ErrMissingEvalFunc is returned when a Node is declared without an EvalFunc.
+var ErrMissingEvalFunc = errors.New("missing eval func")
+
+// FromAdjacencyList constructs a Graph from a map of Node IDs to the IDs of their Next Nodes,
+// as returned by ToAdjacencyList. Each Node uses the EvalFunc with the same ID in evals.
+// Nodes that only appear as a Next Node are also included.
+// If a Node has no EvalFunc, ErrMissingEvalFunc is returned. The Graph is validated as in New.
+func FromAdjacencyList(adj map[string][]string, evals map[string]EvalFunc) (Graph, error) {
+	// Declare Nodes in order of ID so that construction does not depend on map iteration order.
+	ids := make([]string, 0, len(adj))
+	seen := map[string]bool{}
+	for id, next := range adj {
+		for _, id := range append([]string{id}, next...) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+
+	b := NewGraphBuilder()
+	for _, id := range ids {
+		eval, ok := evals[id]
+		if !ok || eval == nil {
+			return nil, fmt.Errorf("%w: %s", ErrMissingEvalFunc, id)
+		}
+		b.Node(id, eval)
+	}
+	for _, id := range ids {
+		for _, next := range adj[id] {
+			b.Edge(id, next)
+		}
+	}
+	return b.Build()
+}
+
+// ErrMalformedLine is returned by NewFromReader for a line that is not in the adjacency format.
+var ErrMalformedLine = errors.New("malformed adjacency line")
+
+// NewFromReader constructs a Graph from an adjacency definition read line by line from r, using the EvalFuncs
+// in evals as for FromAdjacencyList. Each line has a Node ID followed by a colon and the space-separated IDs of
+// its Next Nodes, such as "max: sum". A Node may appear on several lines, and blank lines and lines starting
+// with "#" are ignored. Only the text of the definition is read line by line: the adjacency map and then the
+// complete Graph are built in memory before NewFromReader returns, since a parent of a Node may be declared on
+// any later line, so the Graph must still fit in memory.
+// If a line has no colon or an empty ID, an error wrapping ErrMalformedLine with the line number is returned.
+func NewFromReader(r io.Reader, evals map[string]EvalFunc) (Graph, error) {
+	adj := map[string][]string{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		id, next, ok := strings.Cut(text, ":")
+		if id = strings.TrimSpace(id); !ok || id == "" {
+			return nil, fmt.Errorf("line %d: %w: %q", line, ErrMalformedLine, text)
+		}
+		adj[id] = append(adj[id], strings.Fields(next)...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read adjacency: %w", err)
+	}
+	return FromAdjacencyList(adj, evals)
+}
+
+// Fingerprint returns a hash of the topology of the Graph: its Node IDs and the IDs of each Node's Next Nodes.
+// It does not depend on construction order, EvalFuncs, or evaluation state,
+// so structurally equal Graphs, such as a Graph and its Clone, have the same Fingerprint.
+func (g Graph) Fingerprint() string {
+	adj := g.ToAdjacencyList()
+	ids := make([]string, 0, len(adj))
+	for id := range adj {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	// Hash one line per Node, listing its ID followed by its sorted Next IDs.
+	// Each ID is quoted so that IDs containing separators can't produce the same line.
+	h := sha256.New()
+	for _, id := range ids {
+		next := append([]string{}, adj[id]...)
+		sort.Strings(next)
+		for i := range next {
+			next[i] = strconv.Quote(next[i])
+		}
+		fmt.Fprintf(h, "%q -> %s\n", id, strings.Join(next, " "))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Edge is a directed edge between two Nodes, identified by ID.
+type Edge struct {
+	From, To string
+}
+
+// Edges returns every edge in the Graph, sorted by From ID and then by To ID.
+func (g Graph) Edges() []Edge {
+	edges := []Edge{}
+	for id, node := range g {
+		for _, next := range node.Next {
+			edges = append(edges, Edge{From: id, To: next.ID})
+		}
+	}
+	sortEdges(edges)
+	return edges
+}
+
+// edgeSet returns the set of edges in the Graph.
+func (g Graph) edgeSet() map[Edge]bool {
+	edges := map[Edge]bool{}
+	for id, node := range g {
+		for _, next := range node.Next {
+			edges[Edge{From: id, To: next.ID}] = true
+		}
+	}
+	return edges
+}
+
+// sortEdges sorts edges by From ID, then by To ID.
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+}
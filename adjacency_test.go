@@ -0,0 +1,171 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAdjacencyList(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	adj := graph.ToAdjacencyList()
+	expect := map[string][]string{
+		"1":   {"max"},
+		"2":   {"max"},
+		"3":   {"min"},
+		"4":   {"min"},
+		"max": {"sum"},
+		"min": {"sum"},
+		"sum": {},
+	}
+	if fmt.Sprint(adj) != fmt.Sprint(expect) {
+		t.Fatalf("unexpected adjacency list: want %v but got %v", expect, adj)
+	}
+
+	evals := map[string]EvalFunc{
+		"1":   Constant(1),
+		"2":   Constant(2),
+		"3":   Constant(3),
+		"4":   Constant(4),
+		"max": Max,
+		"min": Min,
+		"sum": Sum,
+	}
+	rebuilt, err := FromAdjacencyList(adj, evals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rebuiltAdj := rebuilt.ToAdjacencyList(); fmt.Sprint(rebuiltAdj) != fmt.Sprint(expect) {
+		t.Fatalf("unexpected adjacency list after round trip: want %v but got %v", expect, rebuiltAdj)
+	}
+
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := rebuilt.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	for id, node := range graph {
+		if result := rebuilt[id].Result; result != node.Result {
+			t.Fatalf("unexpected result for node %s: want %d but got %d", id, node.Result, result)
+		}
+	}
+
+	delete(evals, "sum")
+	if _, err := FromAdjacencyList(adj, evals); !errors.Is(err, ErrMissingEvalFunc) {
+		t.Fatalf("expected ErrMissingEvalFunc but got %v", err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := graph.Clone()
+	if graph.Fingerprint() != clone.Fingerprint() {
+		t.Fatal("expected a graph and its clone to have the same fingerprint")
+	}
+
+	// Construction order does not matter.
+	built, err := NewGraphBuilder().
+		Node("4", Constant(4)).Node("3", Constant(3)).Node("2", Constant(2)).Node("1", Constant(1)).
+		Node("min", Min).Node("max", Max).Node("sum", Sum).
+		Edge("min", "sum").Edge("max", "sum").
+		Edge("4", "min").Edge("3", "min").Edge("2", "max").Edge("1", "max").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if graph.Fingerprint() != built.Fingerprint() {
+		t.Fatal("expected structurally equal graphs to have the same fingerprint")
+	}
+
+	if err := clone.AddEdge("1", "min"); err != nil {
+		t.Fatal(err)
+	}
+	if graph.Fingerprint() == clone.Fingerprint() {
+		t.Fatal("expected adding an edge to change the fingerprint")
+	}
+}
+
+func TestEdges(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []Edge{
+		{From: "1", To: "max"},
+		{From: "2", To: "max"},
+		{From: "3", To: "min"},
+		{From: "4", To: "min"},
+		{From: "max", To: "sum"},
+		{From: "min", To: "sum"},
+	}
+	if edges := graph.Edges(); fmt.Sprint(edges) != fmt.Sprint(expect) {
+		t.Fatalf("unexpected edges: want %v but got %v", expect, edges)
+	}
+}
+
+func TestSuccessorAndPredecessorIDs(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		Name   string
+		Lookup func(string) ([]string, error)
+		ID     string
+		Expect []string
+	}{
+		{Name: "predecessors", Lookup: graph.PredecessorIDs, ID: "sum", Expect: []string{"max", "min"}},
+		{Name: "predecessors", Lookup: graph.PredecessorIDs, ID: "1", Expect: []string{}},
+		{Name: "successors", Lookup: graph.SuccessorIDs, ID: "1", Expect: []string{"max"}},
+		{Name: "successors", Lookup: graph.SuccessorIDs, ID: "sum", Expect: []string{}},
+	} {
+		ids, err := test.Lookup(test.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(test.Expect) {
+			t.Fatalf("unexpected %s of %s: want %v but got %v", test.Name, test.ID, test.Expect, ids)
+		}
+		if _, err := test.Lookup("nope"); !errors.Is(err, ErrUnknownNode) {
+			t.Fatalf("expected ErrUnknownNode from %s but got %v", test.Name, err)
+		}
+	}
+}
+
+func TestNewFromReader(t *testing.T) {
+	definition := `
+# The assignment graph.
+1: max
+2: max
+3: min
+4: min
+max: sum
+min: sum
+`
+	evals := map[string]EvalFunc{
+		"1": Constant(1), "2": Constant(2), "3": Constant(3), "4": Constant(4),
+		"max": Max, "min": Min, "sum": Sum,
+	}
+	graph, err := NewFromReader(strings.NewReader(definition), evals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["sum"].Result; result != 5 {
+		t.Fatalf("unexpected result for node sum: want 5 but got %d", result)
+	}
+
+	if _, err := NewFromReader(strings.NewReader("1: max\nmax sum\n"), evals); !errors.Is(err, ErrMalformedLine) || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected ErrMalformedLine on line 2 but got %v", err)
+	}
+}
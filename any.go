@@ -0,0 +1,26 @@
+package dag
+
+// EvalFuncAny is a form of EvalFunc for heterogeneous pipelines, where results are not necessarily integers.
+// It receives the AnyResult of each parent, which is the boxed Result for Nodes with an EvalFunc or Evaluator,
+// and its result is stored in the Node's AnyResult. If the result is an int, it is also stored in Result;
+// otherwise Result is zero, which is also the input received by Next Nodes that do not use an EvalFuncAny.
+// The channel is closed before the EvalFuncAny is called, as described for EvalFunc.
+type EvalFuncAny func(chan any) any
+
+// anyEvaluator is the Evaluator of a Node with an EvalFuncAny.
+type anyEvaluator struct {
+	fn EvalFuncAny
+}
+
+// Evaluate calls the EvalFuncAny and returns its result if it is an int, or zero otherwise.
+func (e anyEvaluator) Evaluate(ec EvalContext) (int, error) {
+	result, _ := e.fn(ec.anyInputs).(int)
+	return result, nil
+}
+
+// NewAnyNode returns a Node with the given ID and EvalFuncAny, like NewNode.
+func NewAnyNode(id string, eval EvalFuncAny, next ...*Node) *Node {
+	n := newNode(id, anyEvaluator{eval})
+	n.connectAll(next)
+	return n
+}
@@ -0,0 +1,55 @@
+package dag
+
+import "testing"
+
+func TestEvalFuncAny(t *testing.T) {
+	countTrue := NewAnyNode("count", func(inputs chan any) any {
+		count := 0
+		for input := range inputs {
+			if input.(bool) {
+				count++
+			}
+		}
+		return count
+	})
+	isLarge := func(id string) *Node {
+		return NewAnyNode(id, func(inputs chan any) any {
+			return Sum(intInputs(inputs)) > 5
+		}, countTrue)
+	}
+	large, small := isLarge("large"), isLarge("small")
+	graph, err := New(
+		NewNode("3", Constant(3), large),
+		NewNode("4", Constant(4), large),
+		NewNode("1", Constant(1), small),
+		NewNode("2", Constant(2), small),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["large"].AnyResult; result != true {
+		t.Fatalf("unexpected result for node large: want true but got %v", result)
+	}
+	if result := graph["small"].AnyResult; result != false {
+		t.Fatalf("unexpected result for node small: want false but got %v", result)
+	}
+	if result := graph["count"].Result; result != 1 {
+		t.Fatalf("unexpected result for node count: want 1 but got %d", result)
+	}
+	if result := graph["3"].AnyResult; result != 3 {
+		t.Fatalf("expected AnyResult to hold the Result of node 3 but got %v", result)
+	}
+}
+
+// intInputs converts boxed integer inputs to a channel of integers for use with an EvalFunc.
+func intInputs(inputs chan any) chan int {
+	out := make(chan int, len(inputs))
+	for input := range inputs {
+		out <- input.(int)
+	}
+	close(out)
+	return out
+}
@@ -0,0 +1,43 @@
+package dag
+
+import "context"
+
+// EvalHandle tracks an evaluation started by EvaluateAsync.
+type EvalHandle struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	err    error
+}
+
+// EvaluateAsync starts Evaluate in the background and returns a handle to wait for or cancel the evaluation.
+func (g Graph) EvaluateAsync(concurrency int, opts ...EvalOption) *EvalHandle {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &EvalHandle{done: make(chan struct{}), cancel: cancel}
+	go func() {
+		defer cancel()
+		h.err = g.Evaluate(concurrency, append(opts, func(cfg *evalConfig) {
+			cfg.ctx = ctx
+		})...)
+		close(h.done)
+	}()
+	return h
+}
+
+// Wait waits for the evaluation to complete and returns its error, as returned by Evaluate.
+func (h *EvalHandle) Wait() error {
+	<-h.done
+	return h.err
+}
+
+// Done returns a channel that is closed when the evaluation completes.
+func (h *EvalHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Cancel stops the evaluation early. Nodes that have not started running are not evaluated, and fail with
+// an error wrapping context.Canceled, while the EvalContext.Context of running Evaluators is canceled.
+// A running EvalFunc cannot be interrupted, so the evaluation completes once it returns.
+// Cancel has no effect once the evaluation has completed.
+func (h *EvalHandle) Cancel() {
+	h.cancel()
+}
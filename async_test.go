@@ -0,0 +1,56 @@
+package dag
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEvaluateAsync(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := graph.EvaluateAsync(2)
+	select {
+	case <-h.Done():
+	case <-time.After(time.Second):
+		t.Fatal("async evaluation did not complete")
+	}
+	if err := h.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	for id, expect := range map[string]int{"max": 2, "min": 3, "sum": 5} {
+		if result, err := graph.Result(id); err != nil || result != expect {
+			t.Fatalf("unexpected result for node %s: want %d but got %d (%v)", id, expect, result, err)
+		}
+	}
+}
+
+func TestEvaluateAsyncCancel(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	graph, err := New(NewNode("slow", func(_ chan int) int {
+		close(started)
+		<-release
+		return 1
+	}, NewNode("next", Sum)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := graph.EvaluateAsync(2)
+	<-started
+	h.Cancel()
+	close(release)
+
+	// The running Node completes, but its successor is not evaluated.
+	if err := h.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled but got %v", err)
+	}
+	if result, err := graph.Result("slow"); err != nil || result != 1 {
+		t.Fatalf("unexpected result for node slow: want 1 but got %d (%v)", result, err)
+	}
+	if _, err := graph.Result("next"); !errors.Is(err, ErrNotEvaluated) {
+		t.Fatalf("expected next not to be evaluated but got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+)
+
+// GraphBuilder constructs a Graph from Node and edge declarations.
+// Unlike NewNode, no wiring happens until Build is called, so Nodes and edges may be declared in any order.
+// To construct a GraphBuilder, use the NewGraphBuilder function.
+type GraphBuilder struct {
+	nodes []*Node
+	byID  map[string]*Node
+	edges [][2]string
+	err   error
+}
+
+// NewGraphBuilder returns an empty GraphBuilder.
+func NewGraphBuilder() *GraphBuilder {
+	return &GraphBuilder{byID: make(map[string]*Node)}
+}
+
+// ErrDuplicateID is returned when more than one Node is declared with the same ID.
+var ErrDuplicateID = errors.New("duplicate node id")
+
+// ErrEmptyID is returned when a Node has an empty ID.
+var ErrEmptyID = errors.New("empty node id")
+
+// Node declares a Node with the given ID and EvalFunc.
+// Declaring the same ID twice causes Build to return ErrDuplicateID.
+func (b *GraphBuilder) Node(id string, eval EvalFunc) *GraphBuilder {
+	if _, ok := b.byID[id]; ok {
+		if b.err == nil {
+			b.err = fmt.Errorf("%w: %s", ErrDuplicateID, id)
+		}
+		return b
+	}
+	n := newNode(id, eval)
+	b.nodes = append(b.nodes, n)
+	b.byID[id] = n
+	return b
+}
+
+// Edge declares that the output of the Node with ID "from" is sent to the Node with ID "to".
+// Referencing an undeclared Node causes Build to return ErrUnknownNode,
+// and declaring the same edge twice causes Build to return ErrDuplicateEdge.
+func (b *GraphBuilder) Edge(from, to string) *GraphBuilder {
+	b.edges = append(b.edges, [2]string{from, to})
+	return b
+}
+
+// Build wires the declared edges and constructs the Graph using NewWithOptions, which validates it.
+// Build should be called at most once per GraphBuilder.
+func (b *GraphBuilder) Build(opts ...Option) (Graph, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	declared := make(map[[2]string]bool, len(b.edges))
+	for _, edge := range b.edges {
+		for _, id := range edge {
+			if _, ok := b.byID[id]; !ok {
+				return nil, fmt.Errorf("edge %s to %s: %w: %s", edge[0], edge[1], ErrUnknownNode, id)
+			}
+		}
+		if declared[edge] {
+			return nil, fmt.Errorf("%w: %s to %s", ErrDuplicateEdge, edge[0], edge[1])
+		}
+		declared[edge] = true
+	}
+	for _, edge := range b.edges {
+		b.byID[edge[0]].connect(b.byID[edge[1]])
+	}
+	return NewWithOptions(b.nodes, opts...)
+}
@@ -0,0 +1,80 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestGraphBuilder(t *testing.T) {
+	expected, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph, err := NewGraphBuilder().
+		Node("sum", Sum).
+		Node("max", Max).
+		Node("min", Min).
+		Node("1", Constant(1)).
+		Node("2", Constant(2)).
+		Node("3", Constant(3)).
+		Node("4", Constant(4)).
+		Edge("max", "sum").
+		Edge("min", "sum").
+		Edge("1", "max").
+		Edge("2", "max").
+		Edge("3", "min").
+		Edge("4", "min").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert that both Graphs have the same Nodes and edges.
+	if len(graph) != len(expected) {
+		t.Fatalf("expected %d nodes but got %d", len(expected), len(graph))
+	}
+	for id, node := range expected {
+		if _, ok := graph[id]; !ok {
+			t.Fatalf("missing node %s", id)
+		}
+		want, got := make([]string, 0), make([]string, 0)
+		for _, next := range node.Next {
+			want = append(want, next.ID)
+		}
+		for _, next := range graph[id].Next {
+			got = append(got, next.ID)
+		}
+		sort.Strings(want)
+		sort.Strings(got)
+		if fmt.Sprint(want) != fmt.Sprint(got) {
+			t.Fatalf("unexpected edges from node %s: want %v but got %v", id, want, got)
+		}
+	}
+
+	// Assert that both Graphs produce the same results.
+	if err := expected.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	for id, node := range expected {
+		if result := graph[id].Result; result != node.Result {
+			t.Fatalf("unexpected result for node %s: want %d but got %d", id, node.Result, result)
+		}
+	}
+}
+
+func TestGraphBuilderErrors(t *testing.T) {
+	if _, err := NewGraphBuilder().Node("a", Sum).Node("a", Sum).Build(); !errors.Is(err, ErrDuplicateID) {
+		t.Fatalf("expected ErrDuplicateID but got %v", err)
+	}
+	if _, err := NewGraphBuilder().Node("a", Sum).Edge("a", "b").Build(); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+	if _, err := NewGraphBuilder().Node("a", Sum).Node("b", Sum).Build(); !errors.Is(err, ErrDisconnected) {
+		t.Fatalf("expected ErrDisconnected but got %v", err)
+	}
+}
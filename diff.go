@@ -0,0 +1,73 @@
+package dag
+
+import "sort"
+
+// GraphDiff describes the changes in topology from one Graph to another, by Node ID.
+// Each slice is sorted.
+type GraphDiff struct {
+	AddedNodes   []string
+	RemovedNodes []string
+	AddedEdges   []Edge
+	RemovedEdges []Edge
+}
+
+// Empty reports whether the GraphDiff contains no changes.
+func (d GraphDiff) Empty() bool {
+	return len(d.AddedNodes) == 0 && len(d.RemovedNodes) == 0 && len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0
+}
+
+// Diff returns the changes needed to turn the topology of the Graph into the topology of the other Graph.
+// Added Nodes and edges are those in the other Graph but not in this one, and removed Nodes and edges are
+// those in this Graph but not in the other one.
+func (g Graph) Diff(other Graph) GraphDiff {
+	d := GraphDiff{
+		AddedNodes:   []string{},
+		RemovedNodes: []string{},
+		AddedEdges:   []Edge{},
+		RemovedEdges: []Edge{},
+	}
+	for id := range other {
+		if _, ok := g[id]; !ok {
+			d.AddedNodes = append(d.AddedNodes, id)
+		}
+	}
+	for id := range g {
+		if _, ok := other[id]; !ok {
+			d.RemovedNodes = append(d.RemovedNodes, id)
+		}
+	}
+	before, after := g.edgeSet(), other.edgeSet()
+	for edge := range after {
+		if !before[edge] {
+			d.AddedEdges = append(d.AddedEdges, edge)
+		}
+	}
+	for edge := range before {
+		if !after[edge] {
+			d.RemovedEdges = append(d.RemovedEdges, edge)
+		}
+	}
+	sort.Strings(d.AddedNodes)
+	sort.Strings(d.RemovedNodes)
+	sortEdges(d.AddedEdges)
+	sortEdges(d.RemovedEdges)
+	return d
+}
+
+// ResultsDiff compares the Results of the evaluated Nodes of the Graph with prior results by Node ID,
+// such as those returned by EvaluateCopy before the Graph was changed and evaluated again.
+// It returns the old and new Result of each Node whose Result changed, keyed by ID.
+// Nodes missing from prior, and Nodes that have not been evaluated, are not compared.
+func (g Graph) ResultsDiff(prior map[string]int) map[string][2]int {
+	changed := make(map[string][2]int)
+	for id, n := range g {
+		old, ok := prior[id]
+		if !ok || !n.evaluated.Load() {
+			continue
+		}
+		if n.Result != old {
+			changed[id] = [2]int{old, n.Result}
+		}
+	}
+	return changed
+}
@@ -0,0 +1,78 @@
+package dag
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := graph.Diff(graph.Clone()); !d.Empty() {
+		t.Fatalf("expected no changes between a graph and its clone but got %+v", d)
+	}
+
+	changed := graph.Clone()
+	if err := changed.AddEdge("1", "min"); err != nil {
+		t.Fatal(err)
+	}
+	d := graph.Diff(changed)
+	expect := GraphDiff{
+		AddedNodes:   []string{},
+		RemovedNodes: []string{},
+		AddedEdges:   []Edge{{From: "1", To: "min"}},
+		RemovedEdges: []Edge{},
+	}
+	if fmt.Sprintf("%+v", d) != fmt.Sprintf("%+v", expect) {
+		t.Fatalf("unexpected diff: want %+v but got %+v", expect, d)
+	}
+
+	// Diffing in the other direction reports the edge as removed.
+	if reverse := changed.Diff(graph); len(reverse.RemovedEdges) != 1 || reverse.RemovedEdges[0] != (Edge{From: "1", To: "min"}) {
+		t.Fatalf("unexpected removed edges: %v", reverse.RemovedEdges)
+	}
+
+	relabeled, err := graph.Relabel(map[string]string{"sum": "total"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d = graph.Diff(relabeled)
+	if fmt.Sprint(d.AddedNodes, d.RemovedNodes) != "[total] [sum]" {
+		t.Fatalf("unexpected node changes: added %v, removed %v", d.AddedNodes, d.RemovedNodes)
+	}
+	if len(d.AddedEdges) != 2 || len(d.RemovedEdges) != 2 {
+		t.Fatalf("expected 2 added and 2 removed edges but got %v and %v", d.AddedEdges, d.RemovedEdges)
+	}
+}
+
+func TestResultsDiff(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prior, err := graph.EvaluateCopy(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if d := graph.ResultsDiff(prior); len(d) != 0 {
+		t.Fatalf("expected no changed results but got %v", d)
+	}
+
+	// Raising a constant feeding "max" changes "max" and "sum", but not "min" or the other constants.
+	if err := graph.SetEval("1", Constant(7)); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.EvaluateIncremental([]string{"1"}, 2); err != nil {
+		t.Fatal(err)
+	}
+	d := graph.ResultsDiff(prior)
+	expect := map[string][2]int{"1": {1, 7}, "max": {2, 7}, "sum": {5, 10}}
+	if fmt.Sprint(d) != fmt.Sprint(expect) {
+		t.Fatalf("unexpected results diff: want %v but got %v", expect, d)
+	}
+}
@@ -0,0 +1,55 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NodeError is returned when a single Node fails during evaluation.
+type NodeError struct {
+	ID  string
+	Err error
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("node %s: %s", e.ID, e.Err)
+}
+
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError is returned when more than one Node fails during evaluation.
+// errors.Is and errors.As match against each of the contained errors.
+type MultiError struct {
+	Errors []error
+}
+
+func (e *MultiError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Is reports whether any of the contained errors matches the target.
+func (e *MultiError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first contained error that matches the target, and if so, sets the target to that error.
+func (e *MultiError) As(target any) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package dag
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMultiError(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	slow := func(_ chan int) int {
+		<-release
+		return 0
+	}
+
+	sum := NewNode("sum", Sum)
+	graph, err := New(
+		NewNode("slow1", slow, sum),
+		NewNode("slow2", slow, sum),
+		NewNode("fast", Constant(1), sum),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = graph.Evaluate(3, WithNodeTimeout(20*time.Millisecond))
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("expected a MultiError but got %v", err)
+	}
+	if len(multi.Errors) != 2 {
+		t.Fatalf("expected 2 errors but got %d: %s", len(multi.Errors), multi)
+	}
+	failed := map[string]bool{}
+	for _, err := range multi.Errors {
+		var nodeErr *NodeError
+		if !errors.As(err, &nodeErr) {
+			t.Fatalf("expected a NodeError but got %v", err)
+		}
+		failed[nodeErr.ID] = true
+	}
+	if !failed["slow1"] || !failed["slow2"] {
+		t.Fatalf("expected nodes slow1 and slow2 to fail but got %v", failed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the MultiError to match context.DeadlineExceeded: %s", err)
+	}
+	if errors.Is(err, ErrCycle) {
+		t.Fatalf("expected the MultiError not to match ErrCycle: %s", err)
+	}
+}
@@ -1,28 +1,314 @@
 package dag
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 var ErrMinConcurrency = errors.New("concurrency must be at least 1")
 
+// ErrEmptyGraph is returned when evaluating a Graph without any Nodes with WithRejectEmpty.
+var ErrEmptyGraph = errors.New("empty graph")
+
+// ErrInputOrder is returned with WithInputOrderCheck when a Node's inputs arrive in a different order than its InputOrder.
+var ErrInputOrder = errors.New("inputs out of order")
+
 // Evaluate performs a parallel execution of the Graph with the number of workers equal to "concurrency".
 // Results can be read directly from each Node after evaluation via the Node.Result field.
-func (g Graph) Evaluate(concurrency int) error {
+// If a Node fails, its descendants are not evaluated and a *NodeError is returned.
+// If more than one Node fails, a *MultiError containing each failure is returned.
+func (g Graph) Evaluate(concurrency int, opts ...EvalOption) error {
 	if concurrency < 1 {
 		return ErrMinConcurrency
 	}
+	cfg := newEvalConfig(opts)
+	if cfg.rejectEmpty && len(g) == 0 {
+		return ErrEmptyGraph
+	}
+	nodes, err := g.evaluationOrder()
+	if err != nil {
+		return err
+	}
+	if err := g.prepareLevelSlots(cfg); err != nil {
+		return err
+	}
+	if err := g.prepareSkip(nodes, cfg); err != nil {
+		return err
+	}
+	levels, err := g.Levels()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("evaluation started: concurrency=%d order=%v levels=%s", concurrency, nodeIDs(nodes), formatLevels(levels))
+
+	return evaluateNodes(nodes, concurrency, cfg)
+}
+
+// evaluateNodes evaluates the Nodes, which must be in evaluation order, with the given number of workers.
+func evaluateNodes(nodes []*Node, concurrency int, cfg *evalConfig) error {
+	for _, node := range nodes {
+		node.growInputs()
+	}
+	var early map[*Node]chan error
+	if cfg.maxBufferedInputs > 0 {
+		if err := cfg.limitBuffers(nodes); err != nil {
+			return err
+		}
+		early = cfg.startStreaming(nodes)
+	}
+	cfg.startSink(nodes)
+	if cfg.stats != nil {
+		cfg.stats.Workers = make([]WorkerStats, concurrency)
+	}
+	queues := dispatch(nodes, concurrency, cfg.deterministicWorkers)
+
+	wait := &sync.WaitGroup{}
+	errs := &nodeErrors{}
+	progress := &evalProgress{completed: make(map[*Node]bool, len(nodes))}
+
+	// Launch concurrent workers to evaluate Nodes taken from the queue.
+	for i := 0; i < concurrency; i++ {
+		i := i
+		wait.Add(1)
+		cfg.launch(func() {
+			for node := range queues[i] {
+				if done, ok := early[node]; ok {
+					log.Printf("worker %d: waiting for streaming node %s", i, node.ID)
+					errs.add(<-done)
+				} else {
+					log.Printf("worker %d: evaluating node %s", i, node.ID)
+					errs.add(node.evaluate(cfg))
+				}
+				cfg.complete(node)
+				progress.complete(node)
+				if cfg.stats != nil {
+					cfg.stats.Workers[i].Nodes++
+					cfg.stats.Workers[i].Busy += node.busy
+				}
+			}
+			wait.Done()
+		})
+	}
+
+	if cfg.watchdog <= 0 {
+		wait.Wait()
+		return errs.err()
+	}
+	finished := make(chan struct{})
+	go func() {
+		wait.Wait()
+		close(finished)
+	}()
+	ticker := time.NewTicker(cfg.watchdog)
+	defer ticker.Stop()
+	last := 0
+	for {
+		select {
+		case <-finished:
+			return errs.err()
+		case <-ticker.C:
+			count, waiting := progress.status(nodes)
+			if count == last {
+				log.Printf("watchdog: no node completed within %s", cfg.watchdog)
+				return fmt.Errorf("%w: no node completed within %s; waiting: %s",
+					ErrDeadlockSuspected, cfg.watchdog, strings.Join(waiting, ", "))
+			}
+			last = count
+		}
+	}
+}
+
+// ErrDeadlockSuspected is returned by Evaluate with WithWatchdog when no Node completes within the interval.
+var ErrDeadlockSuspected = errors.New("deadlock suspected")
+
+// evalProgress records the Nodes that completed during an evaluation, for WithWatchdog.
+type evalProgress struct {
+	mu        sync.Mutex
+	completed map[*Node]bool
+}
+
+func (p *evalProgress) complete(n *Node) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.completed[n] = true
+}
+
+// status returns the number of completed Nodes, and a description of each of the given Nodes
+// that has not completed with the number of inputs it is still waiting for.
+func (p *evalProgress) status(nodes []*Node) (int, []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	waiting := []string{}
+	for _, n := range nodes {
+		if !p.completed[n] {
+			waiting = append(waiting, fmt.Sprintf("%s (%d of %d inputs remaining)",
+				n.ID, n.indegree-int(n.received.Load()), n.indegree))
+		}
+	}
+	return len(p.completed), waiting
+}
+
+// EvaluateDebug evaluates the Graph like Evaluate, but on the calling goroutine, one Node at a time
+// in the same order that Evaluate dispatches Nodes to workers, while logging each step.
+// It produces the same results as Evaluate and is intended for reproducing problems with stable logs.
+func (g Graph) EvaluateDebug(opts ...EvalOption) error {
+	cfg := newEvalConfig(opts)
+	nodes, err := g.evaluationOrder()
+	if err != nil {
+		return err
+	}
+	if err := g.prepareSkip(nodes, cfg); err != nil {
+		return err
+	}
+
+	log.Printf("debug evaluation started: order=%v", nodeIDs(nodes))
+
+	for _, node := range nodes {
+		node.growInputs()
+	}
+	cfg.startSink(nodes)
+	errs := &nodeErrors{}
+	for i, node := range nodes {
+		log.Printf("debug step %d/%d: node %s has %d of %d inputs buffered",
+			i+1, len(nodes), node.ID, len(node.inputs), node.indegree+node.injected)
+		errs.add(node.evaluate(cfg))
+		cfg.complete(node)
+	}
+	return errs.err()
+}
+
+// prepareLevelSlots creates a semaphore for each level of the Graph if WithLevelConcurrency is set.
+func (g Graph) prepareLevelSlots(cfg *evalConfig) error {
+	if cfg.levelConcurrency <= 0 {
+		return nil
+	}
+	cfg.levelSlots = make(map[*Node]chan struct{}, len(g))
+	return g.WalkLevels(func(_ int, nodes []*Node) error {
+		slots := make(chan struct{}, cfg.levelConcurrency)
+		for _, node := range nodes {
+			cfg.levelSlots[node] = slots
+		}
+		return nil
+	})
+}
+
+// prepareSkip records the Nodes skipped because of WithSkip, given the Nodes in evaluation order.
+func (g Graph) prepareSkip(order []*Node, cfg *evalConfig) error {
+	if len(cfg.skip) == 0 {
+		return nil
+	}
+	cfg.skipped = make(map[*Node]bool, len(cfg.skip))
+	for _, id := range cfg.skip {
+		n, ok := g[id]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownNode, id)
+		}
+		cfg.skipped[n] = true
+	}
+	parents := g.parents()
+	for _, n := range order {
+		if cfg.skipped[n] || len(parents[n]) == 0 {
+			continue
+		}
+		skipped := true
+		for _, parent := range parents[n] {
+			skipped = skipped && cfg.skipped[parent]
+		}
+		cfg.skipped[n] = skipped
+	}
+	return nil
+}
+
+// acquire waits for a slot to run the Node's Evaluator under WithMaxInFlight and WithLevelConcurrency,
+// and returns a function that releases the slots again. Streaming Nodes do not need slots.
+func (cfg *evalConfig) acquire(n *Node) (release func()) {
+	if n.Streaming {
+		return func() {}
+	}
+	semaphores := []chan struct{}{}
+	if cfg.levelSlots != nil {
+		semaphores = append(semaphores, cfg.levelSlots[n])
+	}
+	if cfg.inFlight != nil {
+		semaphores = append(semaphores, cfg.inFlight)
+	}
+	for _, s := range semaphores {
+		s <- struct{}{}
+	}
+	return func() {
+		for _, s := range semaphores {
+			<-s
+		}
+	}
+}
+
+// evaluationOrder returns the Nodes of the Graph in the order they are dispatched for evaluation:
+// topologically sorted, preferring higher priority Nodes among those that are ready.
+func (g Graph) evaluationOrder() ([]*Node, error) {
 	nodes, err := g.TopologicalSort()
 	if err != nil {
-		return fmt.Errorf("topological sort: %w", err)
+		return nil, fmt.Errorf("topological sort: %w", err)
+	}
+	return prioritize(nodes), nil
+}
+
+// nodeErrors records the errors returned by Nodes during evaluation.
+type nodeErrors struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (e *nodeErrors) add(err error) {
+	if err == nil {
+		return
 	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errs = append(e.errs, err)
+}
 
-	log.Printf("evaluation started: concurrency=%d order=%v", concurrency, nodeIDs(nodes))
+// err returns nil if no errors were recorded, the error itself if one was recorded,
+// or a MultiError containing every recorded error.
+func (e *nodeErrors) err() error {
+	switch len(e.errs) {
+	case 0:
+		return nil
+	case 1:
+		return e.errs[0]
+	default:
+		return &MultiError{Errors: e.errs}
+	}
+}
+
+// dispatch returns one queue per worker which together yield every Node in the given order.
+// By default all workers share a single queue. If deterministic is true, each worker instead receives
+// the Nodes whose position in the order is equal to the worker's index modulo the concurrency.
+func dispatch(nodes []*Node, concurrency int, deterministic bool) []chan *Node {
+	queues := make([]chan *Node, concurrency)
+
+	if deterministic {
+		for i := range queues {
+			queues[i] = make(chan *Node, len(nodes)/concurrency+1)
+		}
+		for i, node := range nodes {
+			queues[i%concurrency] <- node
+		}
+		for _, queue := range queues {
+			close(queue)
+		}
+		return queues
+	}
 
-	// Enqueue nodes in topological order.
+	// Enqueue nodes in topological order, preferring higher priority Nodes among those that are ready.
 	queue := make(chan *Node)
 	go func() {
 		for _, node := range nodes {
@@ -30,38 +316,792 @@ func (g Graph) Evaluate(concurrency int) error {
 		}
 		close(queue)
 	}()
+	for i := range queues {
+		queues[i] = queue
+	}
+	return queues
+}
 
-	wait := &sync.WaitGroup{}
+// EvaluateParallel performs Evaluate with concurrency equal to the number of CPUs, capped at the number of Nodes.
+func (g Graph) EvaluateParallel(opts ...EvalOption) error {
+	return g.Evaluate(g.parallelism(), opts...)
+}
 
-	// Launch concurrent workers to evaluate Nodes taken from the queue.
-	for i := 0; i < concurrency; i++ {
-		wait.Add(1)
-		go func(i int) {
-			for node := range queue {
-				log.Printf("worker %d: evaluating node %s", i, node.ID)
-				node.evaluate()
+// MustEvaluate performs Evaluate and panics if it returns an error.
+// It is intended for scripts and tests where a failed evaluation is a bug.
+func (g Graph) MustEvaluate(concurrency int, opts ...EvalOption) {
+	if err := g.Evaluate(concurrency, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// EvaluateWithStats performs Evaluate and returns statistics about the work done by each worker,
+// which show whether the concurrency is well suited to the Graph.
+// The statistics are returned even if evaluation fails.
+func (g Graph) EvaluateWithStats(concurrency int, opts ...EvalOption) (EvalStats, error) {
+	stats := EvalStats{}
+	start := time.Now()
+	err := g.Evaluate(concurrency, append(opts, func(cfg *evalConfig) {
+		cfg.stats = &stats
+	})...)
+	stats.Elapsed = time.Since(start)
+	return stats, err
+}
+
+// EdgeValue is a value sent across the edge from the Node with ID From to its Next Node with ID To.
+type EdgeValue struct {
+	From  string
+	To    string
+	Value int
+}
+
+// edgeLog records the values sent across edges during an evaluation for EvaluateWithInputsLog.
+type edgeLog struct {
+	mu     sync.Mutex
+	values []EdgeValue
+}
+
+func (l *edgeLog) add(from, to *Node) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.values = append(l.values, EdgeValue{From: from.ID, To: to.ID, Value: from.Result})
+	l.mu.Unlock()
+}
+
+// EvaluateWithInputsLog performs Evaluate and returns every value sent from a Node to one of its Next Nodes,
+// in the order they were sent, as an audit trail that can reproduce the computation of each Node.
+// Edges from failed Nodes carry no value and are not logged. The log is returned even if evaluation fails.
+func (g Graph) EvaluateWithInputsLog(concurrency int, opts ...EvalOption) ([]EdgeValue, error) {
+	edges := &edgeLog{}
+	err := g.Evaluate(concurrency, append(opts, func(cfg *evalConfig) {
+		cfg.edgeLog = edges
+	})...)
+	return edges.values, err
+}
+
+// parallelism returns the number of CPUs, capped at the number of Nodes in the Graph and no lower than 1.
+func (g Graph) parallelism() int {
+	concurrency := runtime.NumCPU()
+	if len(g) < concurrency {
+		concurrency = len(g)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return concurrency
+}
+
+// ErrInputsFull is returned when a Node would buffer more inputs than WithMaxBufferedInputs allows.
+var ErrInputsFull = errors.New("inputs buffer full")
+
+// Inject adds an input with the given value to the Node with the given ID, in addition to the inputs
+// it receives from its parents. Inject must be called before the Graph is evaluated.
+// The Node's inputs buffer is enlarged if needed to hold the injected inputs and the parents' inputs.
+// If the Graph does not contain the Node, ErrUnknownNode is returned.
+func (g Graph) Inject(id string, value int) error {
+	n, ok := g[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownNode, id)
+	}
+	n.inject(value)
+	return nil
+}
+
+// pendingInjected returns the inputs injected with Inject that the Node has not consumed yet.
+func (n *Node) pendingInjected() []int {
+	if n.started.Load() || n.evaluated.Load() {
+		return nil
+	}
+	return append([]int(nil), n.Inputs[:n.injected]...)
+}
+
+// inject buffers an injected input with the given value, enlarging the Node's inputs buffer if needed.
+func (n *Node) inject(value int) {
+	n.injected++
+	n.growInputs()
+	if n.anyInputs != nil {
+		n.anyInputs <- value
+	} else {
+		n.inputs <- value
+	}
+	n.Inputs = append(n.Inputs, value)
+}
+
+// ClearResults sets the Result of every Node in the Graph to zero and marks it as not evaluated.
+// It does not prepare the Graph to be evaluated again.
+func (g Graph) ClearResults() {
+	for _, n := range g {
+		n.Result = 0
+		n.AnyResult = nil
+		n.evaluated.Store(false)
+	}
+}
+
+// MapResults replaces the Result of every Node in the Graph with the result of fn, for example to clamp every
+// Result to a range after evaluation. An int AnyResult is replaced as well. It must not be called while the
+// Graph is being evaluated.
+func (g Graph) MapResults(fn func(id string, r int) int) {
+	for id, n := range g {
+		n.Result = fn(id, n.Result)
+		if _, ok := n.AnyResult.(int); ok {
+			n.AnyResult = n.Result
+		}
+	}
+}
+
+// EvaluateBatch evaluates the topology of the Graph once for each set of inputs, and returns the Result
+// of every Node for each run, keyed by Node ID. Each set of inputs maps Node IDs to constant values that
+// replace those Nodes' Evaluators for that run. The Graph itself is not evaluated or modified, and as for
+// EvaluateCopy, every run also evaluates the inputs injected with Inject that the Graph has not consumed yet.
+// The Graph is copied once and the copy is reset between runs, but each run starts its own workers, so that
+// options such as WithDeterministicWorkers and WithWatchdog apply to each run as they do to Evaluate.
+// If an input refers to a Node that is not in the Graph, ErrUnknownNode is returned.
+// If a run fails, evaluation stops and its error is returned.
+func (g Graph) EvaluateBatch(inputs []map[string]int, concurrency int, opts ...EvalOption) ([]map[string]int, error) {
+	for _, values := range inputs {
+		for id := range values {
+			if _, ok := g[id]; !ok {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownNode, id)
 			}
-			wait.Done()
-		}(i)
+		}
+	}
+
+	// Copy the Graph once, and reset the copy before each run.
+	batch := g.Clone()
+	results := make([]map[string]int, 0, len(inputs))
+	for i, values := range inputs {
+		batch.reset()
+		for id, node := range batch {
+			node.eval = g[id].eval
+			if value, ok := values[id]; ok {
+				node.eval = Constant(value)
+			}
+			for _, value := range g[id].pendingInjected() {
+				node.inject(value)
+			}
+		}
+		if err := batch.Evaluate(concurrency, opts...); err != nil {
+			return nil, fmt.Errorf("run %d: %w", i, err)
+		}
+		result := make(map[string]int, len(batch))
+		for id, node := range batch {
+			result[id] = node.Result
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// EvaluateCopy evaluates a copy of the Graph like Evaluate, and returns the Result of every Node keyed by Node ID.
+// The Graph itself is not evaluated or modified, so EvaluateCopy can be called again without preparing it.
+// Inputs injected with Inject that the Graph has not consumed yet are evaluated with the copy as well.
+// If evaluation fails, its error is returned.
+func (g Graph) EvaluateCopy(concurrency int, opts ...EvalOption) (map[string]int, error) {
+	clone := g.Clone()
+	if err := clone.Evaluate(concurrency, opts...); err != nil {
+		return nil, err
+	}
+	results := make(map[string]int, len(clone))
+	for id, node := range clone {
+		results[id] = node.Result
+	}
+	return results, nil
+}
+
+// EvaluateIncremental evaluates only the Nodes with the given IDs and their descendants, after an earlier
+// evaluation of the Graph, for example after replacing a Node's EvalFunc with SetEval. Every other Node
+// keeps its Result, which is sent again to any of its Next Nodes that are evaluated.
+// A Node that is not evaluated again and has no result, because it failed or was never evaluated,
+// is treated as a failed parent, so its evaluated descendants are skipped.
+// If the Graph does not contain one of the Nodes, ErrUnknownNode is returned.
+// Errors from the evaluated Nodes are returned as for Evaluate.
+func (g Graph) EvaluateIncremental(changedIDs []string, concurrency int, opts ...EvalOption) error {
+	if concurrency < 1 {
+		return ErrMinConcurrency
+	}
+	affected := make(map[*Node]bool)
+	for _, id := range changedIDs {
+		err := g.WalkFrom(id, func(current *Node, prev []*Node) error {
+			if affected[current] {
+				return ErrSkipChildren
+			}
+			affected[current] = true
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cfg := newEvalConfig(opts)
+	order, err := g.evaluationOrder()
+	if err != nil {
+		return err
+	}
+	if err := g.prepareLevelSlots(cfg); err != nil {
+		return err
+	}
+	if err := g.prepareSkip(order, cfg); err != nil {
+		return err
+	}
+
+	nodes := make([]*Node, 0, len(affected))
+	for _, n := range order {
+		if affected[n] {
+			// Keep the inputs injected since the last evaluation, which a full evaluation would consume too.
+			pending := n.pendingInjected()
+			n.reset()
+			for _, value := range pending {
+				n.inject(value)
+			}
+			nodes = append(nodes, n)
+		}
+	}
+	// Send the results of the Nodes that are not evaluated again to their affected Next Nodes.
+	for _, n := range order {
+		if affected[n] {
+			continue
+		}
+		for _, next := range n.Next {
+			if !affected[next] {
+				continue
+			}
+			if n.evaluated.Load() {
+				next.receive(n)
+			} else {
+				next.parentFailed.Store(true)
+				next.inputDone()
+			}
+		}
+	}
+
+	log.Printf("incremental evaluation started: concurrency=%d order=%v", concurrency, nodeIDs(nodes))
+
+	return evaluateNodes(nodes, concurrency, cfg)
+}
+
+// EvaluateNode evaluates only the Node with the given ID and its ancestors, and returns the Node's Result.
+// Nodes that the Node does not depend on are not evaluated. The evaluation runs on a copy of those Nodes,
+// so the Graph itself is not evaluated or modified. Inputs injected with Inject into those Nodes are evaluated
+// with the copy as well.
+// If the Graph does not contain the Node, ErrUnknownNode is returned.
+// Errors from the evaluated Nodes are returned as for Evaluate.
+func (g Graph) EvaluateNode(id string, concurrency int, opts ...EvalOption) (int, error) {
+	target, ok := g[id]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownNode, id)
+	}
+	parents := g.parents()
+	ancestors := map[*Node]bool{target: true}
+	stack := []*Node{target}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, parent := range parents[n] {
+			if !ancestors[parent] {
+				ancestors[parent] = true
+				stack = append(stack, parent)
+			}
+		}
+	}
+
+	sub := g.induced(ancestors)
+	if err := sub.Evaluate(concurrency, opts...); err != nil {
+		return 0, err
+	}
+	return sub[id].Result, nil
+}
+
+// EvaluatePartial evaluates the ancestors of the Nodes with the given barrier IDs, stopping at the barrier:
+// the barrier Nodes, their descendants, and Nodes that no barrier Node depends on are not evaluated.
+// The results of the evaluated Nodes are sent to their Next Nodes as usual, so the evaluation can be continued
+// with Resume, for example after inspecting the results.
+// If the Graph does not contain one of the barrier Nodes, ErrUnknownNode is returned.
+// Errors from the evaluated Nodes are returned as for Evaluate, in which case the Graph cannot be resumed.
+func (g Graph) EvaluatePartial(barrier []string, concurrency int, opts ...EvalOption) error {
+	if concurrency < 1 {
+		return ErrMinConcurrency
+	}
+	stopped := make(map[*Node]bool)
+	parents := g.parents()
+	ancestors := make(map[*Node]bool)
+	stack := []*Node{}
+	for _, id := range barrier {
+		n, ok := g[id]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownNode, id)
+		}
+		stack = append(stack, parents[n]...)
+		// Mark the barrier Node and its descendants as stopped.
+		n.walkOnceRecursive(func(*Node, []*Node) error { return nil }, nil, stopped)
+	}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !ancestors[n] {
+			ancestors[n] = true
+			stack = append(stack, parents[n]...)
+		}
+	}
+
+	cfg := newEvalConfig(opts)
+	order, err := g.evaluationOrder()
+	if err != nil {
+		return err
+	}
+	if err := g.prepareLevelSlots(cfg); err != nil {
+		return err
+	}
+	if err := g.prepareSkip(order, cfg); err != nil {
+		return err
+	}
+	nodes := make([]*Node, 0, len(ancestors))
+	for _, n := range order {
+		// Every Node must have room for its inputs, since those that are not evaluated still receive them.
+		n.growInputs()
+		if ancestors[n] && !stopped[n] {
+			nodes = append(nodes, n)
+		}
+	}
+
+	log.Printf("partial evaluation started: concurrency=%d order=%v barrier=%v", concurrency, nodeIDs(nodes), barrier)
+
+	return evaluateNodes(nodes, concurrency, cfg)
+}
+
+// ErrNotResumable is returned by Resume when a Node failed in the evaluation that is being resumed.
+var ErrNotResumable = errors.New("evaluation cannot be resumed")
+
+// Resume evaluates every Node of the Graph that has not been evaluated or started, after EvaluatePartial succeeded.
+// If a Node failed in an earlier evaluation, or was skipped because a parent failed, nothing is evaluated and an
+// error wrapping ErrNotResumable is returned; the Graph must be evaluated from scratch instead.
+// Errors from the evaluated Nodes are returned as for Evaluate.
+func (g Graph) Resume(concurrency int, opts ...EvalOption) error {
+	if concurrency < 1 {
+		return ErrMinConcurrency
+	}
+	cfg := newEvalConfig(opts)
+	order, err := g.evaluationOrder()
+	if err != nil {
+		return err
+	}
+	if err := g.prepareLevelSlots(cfg); err != nil {
+		return err
+	}
+	if err := g.prepareSkip(order, cfg); err != nil {
+		return err
+	}
+	failed := []string{}
+	nodes := make([]*Node, 0, len(order))
+	for _, n := range order {
+		if n.failed.Load() {
+			failed = append(failed, n.ID)
+		}
+		if !n.evaluated.Load() && !n.started.Load() {
+			nodes = append(nodes, n)
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("%w: failed nodes %s", ErrNotResumable, strings.Join(failed, ", "))
+	}
+
+	log.Printf("resumed evaluation started: concurrency=%d order=%v", concurrency, nodeIDs(nodes))
+
+	return evaluateNodes(nodes, concurrency, cfg)
+}
+
+// reset prepares every Node of the Graph to be evaluated again, discarding results and injected inputs.
+func (g Graph) reset() {
+	for _, n := range g {
+		n.reset()
+	}
+}
+
+// reset prepares the Node to be evaluated again, discarding its result and injected inputs.
+func (n *Node) reset() {
+	n.Result = 0
+	n.injected = 0
+	n.arrivals = nil
+	n.Inputs = nil
+	n.inputs = make(chan int, n.inputCapacity())
+	if n.anyInputs != nil {
+		n.anyInputs = make(chan any, n.inputCapacity())
+	}
+	n.AnyResult = nil
+	n.wait = &sync.WaitGroup{}
+	n.wait.Add(n.indegree)
+	n.received.Store(0)
+	n.completed.Store(false)
+	n.started.Store(false)
+	n.failed.Store(false)
+	n.parentFailed.Store(false)
+	n.evaluated.Store(false)
+}
+
+// ErrNotEvaluated is returned when reading the result of a Node that has not been evaluated.
+var ErrNotEvaluated = errors.New("node not evaluated")
+
+// Result returns the Result of the Node with the given ID. Unlike reading Node.Result directly,
+// it is safe to call while the Graph is being evaluated.
+// If the Graph does not contain the Node, ErrUnknownNode is returned.
+// If the Node has not been evaluated, or failed, ErrNotEvaluated is returned.
+func (g Graph) Result(id string) (int, error) {
+	n, ok := g[id]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownNode, id)
+	}
+	if !n.evaluated.Load() {
+		return 0, fmt.Errorf("%w: %s", ErrNotEvaluated, id)
+	}
+	return n.Result, nil
+}
+
+// StarvedNodes returns the evaluated Nodes that have parents but ran without receiving an input from any
+// of them, sorted by ID. In a correctly wired Graph, a Node that is not Streaming only runs once every parent
+// has sent its result, so it is never starved. A Node is reported if its inputs were lost or bypassed,
+// for example if the Node's inputs channel was replaced during evaluation, or if a Streaming Node ran
+// while all of its parents failed. Injected inputs and Nodes completed with SetResult are not counted.
+func (g Graph) StarvedNodes() []*Node {
+	starved := g.Filter(func(n *Node) bool {
+		if !n.evaluated.Load() || n.completed.Load() || n.indegree == 0 {
+			return false
+		}
+		n.receiveMu.Lock()
+		defer n.receiveMu.Unlock()
+		return len(n.Inputs)-n.injected == 0
+	})
+	sort.Slice(starved, func(i, j int) bool { return starved[i].ID < starved[j].ID })
+	return starved
+}
+
+// evaluate waits for the Node's inputs, runs its Evaluator, and sends the result to each Next Node.
+// If the Evaluator fails, or a parent failed, the Next Nodes are notified of the failure instead
+// and will not run their own Evaluators. Only the Node's own failure is returned.
+func (n *Node) evaluate(cfg *evalConfig) error {
+	n.busy = 0
+	n.started.Store(true)
+	if cfg.skipped[n] {
+		log.Printf("skipping node %s: skipped by WithSkip", n.ID)
+		for _, next := range n.Next {
+			next.inputDone()
+		}
+		return nil
+	}
+	if n.Streaming {
+		go func(wait *sync.WaitGroup, inputs chan int, anyInputs chan any) {
+			wait.Wait()
+			closeInputs(inputs, anyInputs)
+		}(n.wait, n.inputs, n.anyInputs)
+	} else {
+		n.wait.Wait()
+		closeInputs(n.inputs, n.anyInputs)
+		if n.parentFailed.Load() {
+			log.Printf("skipping node %s: a parent failed", n.ID)
+			n.failNext()
+			return nil
+		}
+		if err := cfg.ctx.Err(); err != nil {
+			log.Printf("skipping node %s: evaluation canceled", n.ID)
+			n.failNext()
+			return &NodeError{ID: n.ID, Err: err}
+		}
+		if cfg.checkInputOrder {
+			if err := n.checkInputOrder(cfg.skipped); err != nil {
+				log.Printf("node %s failed: %s", n.ID, err)
+				n.failNext()
+				return err
+			}
+		}
+	}
+	release := cfg.acquire(n)
+	start := time.Now()
+	result, err := n.run(cfg)
+	n.busy = time.Since(start)
+	release()
+	if n.Streaming {
+		// Discard any inputs the EvalFunc returned without consuming, so parents never block sending to this Node.
+		go func(inputs chan int, anyInputs chan any) {
+			for range inputs {
+			}
+			if anyInputs != nil {
+				for range anyInputs {
+				}
+			}
+		}(n.inputs, n.anyInputs)
+	}
+	if err == nil {
+		err = n.validate(result)
+	}
+	if err != nil {
+		log.Printf("node %s failed: %s", n.ID, err)
+		n.failNext()
+		return err
+	}
+	n.setResult(result)
+	n.evaluated.Store(true)
+	log.Printf("evaluating node %s (%d inputs): result=%v", n.ID, n.indegree+n.injected, n.AnyResult)
+	for _, next := range n.Next {
+		next.receive(n)
+		cfg.edgeLog.add(n, next)
+	}
+	return nil
+}
+
+// validate checks the result with the Node's OutputValidator, if any, wrapping an error in a NodeError.
+func (n *Node) validate(result any) error {
+	if n.OutputValidator == nil {
+		return nil
+	}
+	output, _ := result.(int)
+	if err := n.OutputValidator(output); err != nil {
+		return &NodeError{ID: n.ID, Err: fmt.Errorf("invalid result %d: %w", output, err)}
+	}
+	return nil
+}
+
+// setResult sets the Node's AnyResult, and its Result if the result is an int.
+func (n *Node) setResult(result any) {
+	n.AnyResult = result
+	n.Result, _ = result.(int)
+}
+
+// closeInputs closes the inputs channels of a Node. The anyInputs channel is nil unless the Node has an EvalFuncAny.
+func closeInputs(inputs chan int, anyInputs chan any) {
+	close(inputs)
+	if anyInputs != nil {
+		close(anyInputs)
+	}
+}
+
+// run calls the Node's Evaluator, bounded by the node timeout if one is configured.
+// When the timeout expires, the Evaluator's context is canceled. If the Evaluator does not return,
+// it is left to finish in the background and its result is discarded.
+func (n *Node) run(cfg *evalConfig) (any, error) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if cfg.nodeTimeout > 0 {
+		ctx, cancel = context.WithTimeout(cfg.ctx, cfg.nodeTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(cfg.ctx)
+	}
+	defer cancel()
+	ec := EvalContext{Context: ctx, NodeID: n.ID, Inputs: n.transformInputs(), Metadata: n.Metadata, anyInputs: n.anyInputs,
+		sources: n.sources(), inputOrder: n.InputOrder}
+
+	if cfg.nodeTimeout <= 0 {
+		return n.call(ec)
+	}
+	type outcome struct {
+		result any
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := n.call(ec)
+		done <- outcome{result, err}
+	}()
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-ctx.Done():
+		return 0, &NodeError{ID: n.ID, Err: ctx.Err()}
+	}
+}
+
+// sources returns the ID of the parent that sent each of the Node's inputs, with an empty ID for injected inputs,
+// or nil if the Node is Streaming or has an InputTransform, so its inputs can't be matched to their parents.
+func (n *Node) sources() []string {
+	if n.Streaming || n.InputTransform != nil {
+		return nil
+	}
+	n.receiveMu.Lock()
+	defer n.receiveMu.Unlock()
+	sources := make([]string, n.injected, n.injected+len(n.arrivals))
+	return append(sources, n.arrivals...)
+}
+
+// transformInputs returns the Node's inputs channel, or a closed channel of its inputs after applying
+// the Node's InputTransform. The inputs channel must already be closed unless the Node is Streaming.
+func (n *Node) transformInputs() chan int {
+	if n.InputTransform == nil || n.Streaming || n.anyInputs != nil {
+		return n.inputs
+	}
+	values := make([]int, 0, len(n.inputs))
+	for input := range n.inputs {
+		values = append(values, input)
+	}
+	values = n.InputTransform(values)
+	transformed := make(chan int, len(values))
+	for _, value := range values {
+		transformed <- value
+	}
+	close(transformed)
+	return transformed
+}
+
+// call calls the Node's Evaluator, wrapping any error in a NodeError.
+// The result is an int unless the Node has an EvalFuncAny.
+func (n *Node) call(ec EvalContext) (any, error) {
+	var result any
+	var err error
+	if eval, ok := n.eval.(anyEvaluator); ok {
+		result, err = eval.fn(ec.anyInputs), nil
+	} else {
+		result, err = n.eval.Evaluate(ec)
+	}
+	if err != nil {
+		return 0, &NodeError{ID: n.ID, Err: err}
+	}
+	return result, nil
+}
+
+// inputCapacity returns the buffer size needed for the Node's inputs: MaxIndegree, or enough for every input
+// if the Node has more. A Streaming Node needs it too, since its parents may send before a worker takes it.
+func (n *Node) inputCapacity() int {
+	if inputs := n.indegree + n.injected; inputs > MaxIndegree {
+		return inputs
+	}
+	return MaxIndegree
+}
+
+// growInputs replaces the Node's inputs buffer with a larger one holding the same inputs if it is
+// smaller than inputCapacity. It must be called before any Node of the Graph is evaluated.
+func (n *Node) growInputs() {
+	size := n.inputCapacity()
+	if size <= cap(n.inputs) {
+		return
+	}
+	grown := make(chan int, size)
+	for len(n.inputs) > 0 {
+		grown <- <-n.inputs
+	}
+	n.inputs = grown
+	if n.anyInputs != nil {
+		grownAny := make(chan any, size)
+		for len(n.anyInputs) > 0 {
+			grownAny <- <-n.anyInputs
+		}
+		n.anyInputs = grownAny
+	}
+}
+
+// limitBuffers enforces WithMaxBufferedInputs: it returns an error wrapping ErrInputsFull if a Node that is not
+// Streaming would buffer more inputs than allowed, and otherwise limits the buffer of each Streaming Node.
+func (cfg *evalConfig) limitBuffers(nodes []*Node) error {
+	limit := cfg.maxBufferedInputs
+	for _, n := range nodes {
+		if !n.Streaming {
+			if inputs := n.indegree + n.injected; inputs > limit {
+				return fmt.Errorf("%w: node %s would buffer %d inputs, more than %d", ErrInputsFull, n.ID, inputs, limit)
+			}
+			continue
+		}
+		if buffered := len(n.inputs) + len(n.anyInputs); buffered > limit {
+			return fmt.Errorf("%w: node %s already buffers %d inputs, more than %d", ErrInputsFull, n.ID, buffered, limit)
+		}
+		n.shrinkInputs(limit)
+	}
+	return nil
+}
+
+// startStreaming starts evaluating each Streaming Node on its own goroutine, so that it consumes its inputs
+// before any worker takes it and a parent never blocks on a buffer limited by WithMaxBufferedInputs.
+// It returns a channel per Streaming Node that receives its error, which the worker taking the Node waits for.
+func (cfg *evalConfig) startStreaming(nodes []*Node) map[*Node]chan error {
+	early := map[*Node]chan error{}
+	for _, n := range nodes {
+		if !n.Streaming {
+			continue
+		}
+		done := make(chan error, 1)
+		early[n] = done
+		go func(n *Node) {
+			done <- n.evaluate(cfg)
+		}(n)
+	}
+	return early
+}
+
+// shrinkInputs replaces the Node's inputs buffer with one of the given size holding the same inputs,
+// if it is larger. The buffered inputs must fit.
+func (n *Node) shrinkInputs(size int) {
+	if cap(n.inputs) <= size {
+		return
+	}
+	shrunk := make(chan int, size)
+	for len(n.inputs) > 0 {
+		shrunk <- <-n.inputs
+	}
+	n.inputs = shrunk
+	if n.anyInputs != nil {
+		shrunkAny := make(chan any, size)
+		for len(n.anyInputs) > 0 {
+			shrunkAny <- <-n.anyInputs
+		}
+		n.anyInputs = shrunkAny
 	}
+}
 
-	wait.Wait()
+// receive sends the result of the parent to the Node: its AnyResult if the Node has an EvalFuncAny,
+// or its Result otherwise.
+func (n *Node) receive(parent *Node) {
+	// Hold the lock while sending so the recorded inputs match the order of the inputs channel.
+	n.receiveMu.Lock()
+	n.arrivals = append(n.arrivals, parent.ID)
+	n.Inputs = append(n.Inputs, parent.Result)
+	n.send(parent)
+	n.receiveMu.Unlock()
+	n.inputDone()
+}
 
+func (n *Node) send(parent *Node) {
+	if n.anyInputs != nil {
+		n.anyInputs <- parent.AnyResult
+	} else {
+		n.inputs <- parent.Result
+	}
+}
+
+// checkInputOrder returns ErrInputOrder if the Node's inputs were not sent in its declared InputOrder.
+// Parents skipped by WithSkip send no input and are left out of the expected order.
+func (n *Node) checkInputOrder(skipped map[*Node]bool) error {
+	if n.InputOrder == nil {
+		return nil
+	}
+	skippedIDs := make(map[string]bool, len(skipped))
+	for node := range skipped {
+		if skipped[node] {
+			skippedIDs[node.ID] = true
+		}
+	}
+	expected := make([]string, 0, len(n.InputOrder))
+	for _, id := range n.InputOrder {
+		if !skippedIDs[id] {
+			expected = append(expected, id)
+		}
+	}
+	n.receiveMu.Lock()
+	defer n.receiveMu.Unlock()
+	if fmt.Sprint(n.arrivals) != fmt.Sprint(expected) {
+		return &NodeError{ID: n.ID, Err: fmt.Errorf("%w: expected %v but got %v", ErrInputOrder, expected, n.arrivals)}
+	}
 	return nil
 }
 
-func (n *Node) evaluate() {
-	n.wait.Wait()
-	close(n.inputs)
-	n.Result = n.eval(n.inputs)
-	log.Printf("evaluating node %s (%d inputs): result=%d", n.ID, n.indegree, n.Result)
+// failNext notifies each Next Node that one of its parents failed, in place of sending a result.
+func (n *Node) failNext() {
+	n.failed.Store(true)
 	for _, next := range n.Next {
-		next.receive(n.Result)
+		next.parentFailed.Store(true)
+		next.inputDone()
 	}
 }
 
-func (n *Node) receive(input int) {
-	n.inputs <- input
+// inputDone records that one of the Node's parents has sent its input or failed.
+func (n *Node) inputDone() {
+	n.received.Add(1)
 	n.wait.Done()
 }
 
@@ -72,6 +1112,16 @@ func Constant(n int) EvalFunc {
 	}
 }
 
+// Passthrough is an EvalFunc that returns its sole input unchanged, or zero if there are no inputs.
+// It is the canonical relay for fanning a single value out to several Next Nodes.
+// If there is more than one input, the first one received is returned.
+func Passthrough(inputs chan int) int {
+	output := <-inputs
+	for range inputs {
+	}
+	return output
+}
+
 // Max is an EvalFunc that returns the highest input or zero if there are no inputs.
 func Max(inputs chan int) (output int) {
 	for input := range inputs {
@@ -82,6 +1132,23 @@ func Max(inputs chan int) (output int) {
 	return
 }
 
+// MaxOr returns an EvalFunc that returns the highest input, or def if there are no inputs.
+// Unlike Max, the result can be negative, so MaxOr(math.MinInt) is the identity for fan-in of any values.
+func MaxOr(def int) EvalFunc {
+	return func(inputs chan int) int {
+		output, ok := <-inputs
+		if !ok {
+			return def
+		}
+		for input := range inputs {
+			if input > output {
+				output = input
+			}
+		}
+		return output
+	}
+}
+
 // Min is an EvalFunc that returns the lowest input or zero if there are no inputs.
 func Min(inputs chan int) int {
 	output, ok := <-inputs
@@ -96,6 +1163,45 @@ func Min(inputs chan int) int {
 	return output
 }
 
+// MinOr returns an EvalFunc that returns the lowest input, or def if there are no inputs.
+func MinOr(def int) EvalFunc {
+	return func(inputs chan int) int {
+		output, ok := <-inputs
+		if !ok {
+			return def
+		}
+		for input := range inputs {
+			if input < output {
+				output = input
+			}
+		}
+		return output
+	}
+}
+
+// ArgMax returns the position of the highest input, or -1 if there are no inputs. If several inputs are equal to
+// the highest, the first one wins. Used as an OrderedFunc, position i is the input of the parent InputOrder[i],
+// so the result identifies the winning parent whatever order the parents finish in.
+func ArgMax(ordered []int) int {
+	return argBest(ordered, func(a, b int) bool { return a > b })
+}
+
+// ArgMin is like ArgMax but returns the position of the lowest input.
+func ArgMin(ordered []int) int {
+	return argBest(ordered, func(a, b int) bool { return a < b })
+}
+
+// argBest returns the position of the first input that no later input is better than, or -1 if there are no inputs.
+func argBest(inputs []int, better func(a, b int) bool) int {
+	best := -1
+	for i, input := range inputs {
+		if best < 0 || better(input, inputs[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
 // Sum is an EvalFunc that returns the sum of the inputs or zero if there are no inputs.
 func Sum(inputs chan int) (output int) {
 	for input := range inputs {
@@ -103,3 +1209,190 @@ func Sum(inputs chan int) (output int) {
 	}
 	return
 }
+
+// SumOr returns an EvalFunc that returns the sum of the inputs, or def if there are no inputs.
+func SumOr(def int) EvalFunc {
+	return func(inputs chan int) int {
+		output, ok := <-inputs
+		if !ok {
+			return def
+		}
+		for input := range inputs {
+			output += input
+		}
+		return output
+	}
+}
+
+// And is an EvalFunc that returns the bitwise AND of the inputs, or -1 (all bits set) if there are no inputs.
+func And(inputs chan int) int {
+	output := -1
+	for input := range inputs {
+		output &= input
+	}
+	return output
+}
+
+// Or is an EvalFunc that returns the bitwise OR of the inputs, or zero if there are no inputs.
+func Or(inputs chan int) (output int) {
+	for input := range inputs {
+		output |= input
+	}
+	return
+}
+
+// Xor is an EvalFunc that returns the bitwise XOR of the inputs, or zero if there are no inputs.
+func Xor(inputs chan int) (output int) {
+	for input := range inputs {
+		output ^= input
+	}
+	return
+}
+
+// Any is an EvalFunc that returns 1 if any input is nonzero, or zero otherwise.
+// It stops reading as soon as it receives a nonzero input, so on a Streaming Node it returns without
+// waiting for the remaining parents.
+func Any(inputs chan int) int {
+	for input := range inputs {
+		if input != 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+// ReduceIndexed returns an EvalFunc that folds the inputs into an accumulator, starting from initial.
+// For each input, fn receives the accumulator, the input, and the number of inputs received before it,
+// and returns the new accumulator. The final accumulator is the result, or initial if there are no inputs.
+func ReduceIndexed(initial int, fn func(acc int, in int, index int) int) EvalFunc {
+	return func(inputs chan int) int {
+		acc, index := initial, 0
+		for input := range inputs {
+			acc = fn(acc, input, index)
+			index++
+		}
+		return acc
+	}
+}
+
+// ParallelReduce returns an EvalFunc that folds the inputs into an accumulator like ReduceIndexed, but splits them
+// into chunks of the given size that are reduced in parallel, then folds the partial results in order.
+// Each chunk starts from initial, so initial must be an identity of fn, such as 0 for addition, and fn must be
+// associative, like the reducers Sum, Min and Max. The result is initial if there are no inputs.
+// The inputs are drained before reducing, so it only speeds up an fn that is expensive compared to receiving
+// an input. If chunk is less than 1, the inputs are reduced as a single chunk.
+func ParallelReduce(initial int, fn func(acc int, in int) int, chunk int) EvalFunc {
+	return func(inputs chan int) int {
+		values := Collect(inputs)
+		size := chunk
+		if size < 1 || size > len(values) {
+			size = len(values)
+		}
+		if size == 0 {
+			return initial
+		}
+		partials := make([]int, (len(values)+size-1)/size)
+		wait := &sync.WaitGroup{}
+		for i := range partials {
+			end := (i + 1) * size
+			if end > len(values) {
+				end = len(values)
+			}
+			wait.Add(1)
+			go func(i int, values []int) {
+				defer wait.Done()
+				acc := initial
+				for _, value := range values {
+					acc = fn(acc, value)
+				}
+				partials[i] = acc
+			}(i, values[i*size:end])
+		}
+		wait.Wait()
+		acc := initial
+		for _, partial := range partials {
+			acc = fn(acc, partial)
+		}
+		return acc
+	}
+}
+
+// WeightedAverage returns an OrderedFunc that computes sum(weights[i] * input_i) / sum(weights[i]) over the inputs
+// using integer division, where input_i is the input of the parent InputOrder[i] of the Node, whatever order the
+// parents finish in. Inputs beyond the given weights have a weight of zero.
+// If there are no inputs or their total weight is zero, the result is zero.
+func WeightedAverage(weights ...int) OrderedFunc {
+	return func(ordered []int) int {
+		sum, total := 0, 0
+		for i, input := range ordered {
+			if i < len(weights) {
+				sum += weights[i] * input
+				total += weights[i]
+			}
+		}
+		if total == 0 {
+			return 0
+		}
+		return sum / total
+	}
+}
+
+// ErrOverflow is returned when a result does not fit in an int.
+var ErrOverflow = errors.New("integer overflow")
+
+// ProductChecked is an EvalFuncWithError that returns the product of the inputs, or one, the empty product,
+// if there are no inputs.
+// If the product overflows int, an error wrapping ErrOverflow is returned.
+// The remaining inputs are still drained.
+func ProductChecked(inputs chan int) (int, error) {
+	product, ok := <-inputs
+	if !ok {
+		return 1, nil
+	}
+	var err error
+	for input := range inputs {
+		if err != nil {
+			continue
+		}
+		if product, err = multiplyChecked(product, input); err != nil {
+			product = 0
+		}
+	}
+	return product, err
+}
+
+// multiplyChecked returns a*b, or ErrOverflow if the result does not fit in an int.
+func multiplyChecked(a, b int) (int, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	product := a * b
+	// Dividing by -1 would itself overflow for math.MinInt, so check that case separately.
+	if (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) || product/b != a {
+		return 0, fmt.Errorf("%w: %d * %d", ErrOverflow, a, b)
+	}
+	return product, nil
+}
+
+// Collect drains the inputs into a slice.
+func Collect(inputs chan int) []int {
+	out := make([]int, 0, len(inputs))
+	for input := range inputs {
+		out = append(out, input)
+	}
+	return out
+}
+
+// CollectFunc returns an EvalFunc that collects the inputs into a slice and passes them to fn.
+// It is useful for reducers that need random access to every input, such as sorting or percentiles.
+func CollectFunc(fn func([]int) int) EvalFunc {
+	return func(inputs chan int) int {
+		return fn(Collect(inputs))
+	}
+}
+
+// PackFunc returns an OrderedFunc that passes the inputs to combine in the Node's InputOrder, whatever order
+// its parents finish in, for positional combinations such as packing several inputs into the bits of one result.
+func PackFunc(combine func(ordered []int) int) OrderedFunc {
+	return OrderedFunc(combine)
+}
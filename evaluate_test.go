@@ -1,9 +1,20 @@
 package dag
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func assignmentGraph() (Graph, error) {
@@ -141,3 +152,1590 @@ func TestMinConcurrency(t *testing.T) {
 		t.Fail()
 	}
 }
+
+// inFlightCounter tracks the highest number of Nodes evaluating at the same time.
+type inFlightCounter struct {
+	current, max atomic.Int32
+}
+
+// constant returns an EvalFunc that returns n after being counted as in flight for a millisecond.
+func (c *inFlightCounter) constant(n int) EvalFunc {
+	return func(_ chan int) int {
+		current := c.current.Add(1)
+		for {
+			prev := c.max.Load()
+			if current <= prev || c.max.CompareAndSwap(prev, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		c.current.Add(-1)
+		return n
+	}
+}
+
+// wideGraph returns a Graph of 8 counted constants from 0 to 7, all connected to the "sum" Node.
+func (c *inFlightCounter) wideGraph() (Graph, error) {
+	sum := NewNode("sum", Sum)
+	heads := make([]*Node, 8)
+	for i := range heads {
+		heads[i] = NewNode(fmt.Sprint(i), c.constant(i), sum)
+	}
+	return New(heads...)
+}
+
+func TestEvaluateParallel(t *testing.T) {
+	// Each constant waits until as many constants are running as EvaluateParallel should run at once,
+	// so the evaluation only completes quickly if it uses that many workers.
+	const constants = 8
+	expect := runtime.NumCPU()
+	if expect > constants {
+		expect = constants
+	}
+	var running, max atomic.Int32
+	gate, open := make(chan struct{}), sync.Once{}
+	sum := NewNode("sum", Sum)
+	heads := make([]*Node, constants)
+	for i := range heads {
+		i := i
+		heads[i] = NewNode(fmt.Sprint(i), func(_ chan int) int {
+			current := running.Add(1)
+			defer running.Add(-1)
+			for prev := max.Load(); current > prev && !max.CompareAndSwap(prev, current); prev = max.Load() {
+			}
+			if current == int32(expect) {
+				open.Do(func() { close(gate) })
+			}
+			select {
+			case <-gate:
+			case <-time.After(time.Second):
+			}
+			return i
+		}, sum)
+	}
+	graph, err := New(heads...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.EvaluateParallel(); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["sum"].Result; result != 28 {
+		t.Fatalf("unexpected result for node sum: want 28 but got %d", result)
+	}
+	if observed := int(max.Load()); observed != expect {
+		t.Fatalf("expected %d nodes in flight but got %d", expect, observed)
+	}
+}
+
+func TestMaxInFlight(t *testing.T) {
+	counter := &inFlightCounter{}
+	graph, err := counter.wideGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(8, WithMaxInFlight(2)); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["sum"].Result; result != 28 {
+		t.Fatalf("unexpected result for node sum: want 28 but got %d", result)
+	}
+	if max := counter.max.Load(); max > 2 {
+		t.Fatalf("expected at most 2 nodes in flight but got %d", max)
+	}
+}
+
+func TestPriority(t *testing.T) {
+	order := []string{}
+	record := func(id string) EvalFunc {
+		return func(_ chan int) int {
+			order = append(order, id)
+			return 0
+		}
+	}
+	sum := NewNode("sum", record("sum"))
+	low := NewNode("low", record("low"), sum)
+	high := NewNode("high", record("high"), sum)
+	high.Priority = 5
+	graph, err := New(low, high)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(1); err != nil {
+		t.Fatal(err)
+	}
+	if expect := []string{"high", "low", "sum"}; fmt.Sprint(order) != fmt.Sprint(expect) {
+		t.Fatalf("unexpected evaluation order: want %v but got %v", expect, order)
+	}
+}
+
+func TestCollectFunc(t *testing.T) {
+	spread := NewNode("range", CollectFunc(func(inputs []int) int {
+		if len(inputs) == 0 {
+			return 0
+		}
+		sort.Ints(inputs)
+		return inputs[len(inputs)-1] - inputs[0]
+	}))
+	graph, err := New(
+		NewNode("3", Constant(3), spread),
+		NewNode("9", Constant(9), spread),
+		NewNode("5", Constant(5), spread),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["range"].Result; result != 6 {
+		t.Fatalf("unexpected result for node range: want 6 but got %d", result)
+	}
+}
+
+func TestDeterministicWorkers(t *testing.T) {
+	// Capture the log output of each run to read the worker assigned to each Node.
+	defer log.SetOutput(log.Writer())
+	assignment := regexp.MustCompile(`worker (\d+): evaluating node (\S+)`)
+
+	const concurrency = 3
+	var first map[string]string
+	for run := 0; run < 5; run++ {
+		out := &bytes.Buffer{}
+		log.SetOutput(out)
+
+		graph, err := assignmentGraph()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := graph.Evaluate(concurrency, WithDeterministicWorkers()); err != nil {
+			t.Fatal(err)
+		}
+
+		workers := map[string]string{}
+		for _, match := range assignment.FindAllStringSubmatch(out.String(), -1) {
+			workers[match[2]] = match[1]
+		}
+		if len(workers) != len(graph) {
+			t.Fatalf("expected %d worker assignments but got %d", len(graph), len(workers))
+		}
+
+		// Assert that workers were assigned by position in the evaluation order.
+		order, err := graph.TopologicalSort()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, node := range prioritize(order) {
+			if worker, expect := workers[node.ID], fmt.Sprint(i%concurrency); worker != expect {
+				t.Fatalf("run %d: unexpected worker for node %s: want %s but got %s", run, node.ID, expect, worker)
+			}
+		}
+
+		// Assert that every run produces the same assignment.
+		if first == nil {
+			first = workers
+		} else if fmt.Sprint(first) != fmt.Sprint(workers) {
+			t.Fatalf("run %d: worker assignment changed: want %v but got %v", run, first, workers)
+		}
+	}
+}
+
+func TestClearResults(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	graph.ClearResults()
+	for id, node := range graph {
+		if node.Result != 0 {
+			t.Fatalf("unexpected result for node %s: want 0 but got %d", id, node.Result)
+		}
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	release := make(chan struct{})
+	done := make(chan struct{})
+
+	// The streaming Node returns as soon as it receives a 1.
+	out := NewNode("out", func(inputs chan int) int {
+		defer close(done)
+		return Max(inputs)
+	})
+	stream := NewNode("stream", func(inputs chan int) int {
+		for input := range inputs {
+			if input == 1 {
+				return input
+			}
+		}
+		return 0
+	}, out)
+	stream.Streaming = true
+	slow := NewNode("slow", func(_ chan int) int {
+		<-release
+		return 2
+	}, stream)
+	graph, err := New(NewNode("fast", Constant(1), stream), slow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evaluated := make(chan error)
+	go func() {
+		evaluated <- graph.Evaluate(3)
+	}()
+
+	// The successor of the streaming Node must complete while the slow Node is still running.
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("streaming node waited for all inputs")
+	}
+	close(release)
+	if err := <-evaluated; err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["out"].Result; result != 1 {
+		t.Fatalf("unexpected result for node out: want 1 but got %d", result)
+	}
+}
+
+func TestMaxBufferedInputs(t *testing.T) {
+	// The streaming Node never holds more than 2 buffered inputs from its 20 parents.
+	var buffered int
+	sum := NewNode("sum", func(inputs chan int) int {
+		buffered = cap(inputs)
+		return Sum(inputs)
+	})
+	sum.Streaming = true
+	roots := make([]*Node, 20)
+	for i := range roots {
+		roots[i] = NewNode(fmt.Sprint(i), Constant(i), sum)
+	}
+	graph, err := New(roots...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evaluated := make(chan error)
+	go func() {
+		evaluated <- graph.Evaluate(2, WithMaxBufferedInputs(2))
+	}()
+	select {
+	case err := <-evaluated:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("evaluation with a bounded buffer did not complete")
+	}
+	if result := graph["sum"].Result; result != 190 {
+		t.Fatalf("unexpected result for node sum: want 190 but got %d", result)
+	}
+	if buffered > 2 {
+		t.Fatalf("expected at most 2 buffered inputs but the buffer holds %d", buffered)
+	}
+
+	// A Node that is not Streaming must buffer every input, so it is rejected.
+	graph, err = assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2, WithMaxBufferedInputs(1)); !errors.Is(err, ErrInputsFull) {
+		t.Fatalf("expected ErrInputsFull but got %v", err)
+	}
+	if evaluated := graph.Filter(func(n *Node) bool { return n.evaluated.Load() }); len(evaluated) != 0 {
+		t.Fatalf("expected no nodes to be evaluated but got %v", nodeIDs(evaluated))
+	}
+}
+
+func TestMaxBufferedInputsSingleWorker(t *testing.T) {
+	// The only worker evaluates both parents before it takes the streaming Node, so the second parent
+	// used to block on the full buffer forever.
+	sum := NewNode("sum", Sum)
+	sum.Streaming = true
+	graph, err := New(NewNode("1", Constant(1), sum), NewNode("2", Constant(2), sum))
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivered := []string{}
+	evaluated := make(chan error)
+	go func() {
+		evaluated <- graph.Evaluate(1, WithMaxBufferedInputs(1), WithDeterministicWorkers(), WithOrderedSink(),
+			WithResultSink(func(id string, _ int) {
+				delivered = append(delivered, id)
+			}))
+	}()
+	select {
+	case err := <-evaluated:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("evaluation with a bounded buffer deadlocked")
+	}
+	if result := graph["sum"].Result; result != 3 {
+		t.Fatalf("unexpected result for node sum: want 3 but got %d", result)
+	}
+	order, err := graph.TopologicalSort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(delivered) != fmt.Sprint(nodeIDs(order)) {
+		t.Fatalf("unexpected delivery order: want %v but got %v", nodeIDs(order), delivered)
+	}
+}
+
+func TestAnyShortCircuit(t *testing.T) {
+	release := make(chan struct{})
+	done := make(chan struct{})
+	out := NewNode("out", func(inputs chan int) int {
+		defer close(done)
+		return Max(inputs)
+	})
+	any := NewNode("any", Any, out)
+	any.Streaming = true
+	slow := NewNode("slow", func(_ chan int) int {
+		<-release
+		return 0
+	}, any)
+	graph, err := New(
+		NewNode("1", Constant(1), any),
+		NewNode("0a", Constant(0), any),
+		NewNode("0b", Constant(0), any),
+		slow,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evaluated := make(chan error)
+	go func() {
+		// A buffer smaller than the fan-in only lets the remaining parents complete if the inputs
+		// the Any Node did not consume are discarded.
+		evaluated <- graph.Evaluate(6, WithMaxBufferedInputs(1))
+	}()
+
+	// The successor of the Any Node must complete while the slow Node is still running.
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("any node waited for all inputs")
+	}
+	close(release)
+	if err := <-evaluated; err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["out"].Result; result != 1 {
+		t.Fatalf("unexpected result for node out: want 1 but got %d", result)
+	}
+}
+
+func TestNodeTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	sum := NewNode("sum", Sum)
+	slow := NewNode("slow", func(_ chan int) int {
+		<-release
+		return 2
+	}, sum)
+	double := NewNode("double", CollectFunc(func(inputs []int) int {
+		return 2 * inputs[0]
+	}), sum)
+	graph, err := New(NewNode("fast", Constant(1), double), slow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = graph.Evaluate(4, WithNodeTimeout(20*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded but got %v", err)
+	}
+	if !strings.Contains(err.Error(), "slow") {
+		t.Fatalf("expected error to name node slow: %s", err)
+	}
+	for id, expect := range map[string]int{"fast": 1, "double": 2, "slow": 0, "sum": 0} {
+		if result := graph[id].Result; result != expect {
+			t.Fatalf("unexpected result for node %s: want %d but got %d", id, expect, result)
+		}
+	}
+}
+
+func TestInject(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Inject("sum", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["sum"].Result; result != 15 {
+		t.Fatalf("unexpected result for node sum: want 15 but got %d", result)
+	}
+	if err := graph.Inject("nope", 10); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+}
+
+func TestInjectGrowsInputs(t *testing.T) {
+	defer func(size int) { MaxIndegree = size }(MaxIndegree)
+	MaxIndegree = 2
+
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Inject("sum", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(1); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["sum"].Result; result != 15 {
+		t.Fatalf("unexpected result for node sum: want 15 but got %d", result)
+	}
+
+	// A Node whose fan-in already fills MaxIndegree still accepts injected inputs.
+	MaxIndegree = 10
+	sum := NewNode("sum", Sum)
+	heads := make([]*Node, MaxIndegree)
+	for i := range heads {
+		heads[i] = NewNode(fmt.Sprint(i), Constant(1), sum)
+	}
+	graph, err = New(heads...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Inject("sum", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(1); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["sum"].Result; result != 20 {
+		t.Fatalf("unexpected result for node sum: want 20 but got %d", result)
+	}
+}
+
+func TestResult(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := graph.Result("sum"); !errors.Is(err, ErrNotEvaluated) {
+		t.Fatalf("expected ErrNotEvaluated but got %v", err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	result, err := graph.Result("sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != 5 {
+		t.Fatalf("unexpected result for node sum: want 5 but got %d", result)
+	}
+	if _, err := graph.Result("nope"); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+	graph.ClearResults()
+	if _, err := graph.Result("sum"); !errors.Is(err, ErrNotEvaluated) {
+		t.Fatalf("expected ErrNotEvaluated after ClearResults but got %v", err)
+	}
+}
+
+// inputs returns a closed channel containing the given values, as passed to an EvalFunc.
+func inputs(values ...int) chan int {
+	ch := make(chan int, len(values))
+	for _, v := range values {
+		ch <- v
+	}
+	close(ch)
+	return ch
+}
+
+func TestBitwise(t *testing.T) {
+	for _, test := range []struct {
+		Name   string
+		Eval   EvalFunc
+		Inputs []int
+		Expect int
+	}{
+		{Name: "or", Eval: Or, Inputs: []int{1, 2, 4}, Expect: 7},
+		{Name: "and", Eval: And, Inputs: []int{6, 3}, Expect: 2},
+		{Name: "xor", Eval: Xor, Inputs: []int{5, 5, 2}, Expect: 2},
+		{Name: "empty or", Eval: Or, Expect: 0},
+		{Name: "empty and", Eval: And, Expect: -1},
+		{Name: "empty xor", Eval: Xor, Expect: 0},
+	} {
+		if result := test.Eval(inputs(test.Inputs...)); result != test.Expect {
+			t.Fatalf("%s: want %d but got %d", test.Name, test.Expect, result)
+		}
+	}
+}
+
+func TestEvaluateDebug(t *testing.T) {
+	order := []string{}
+	record := func(id string, eval EvalFunc) EvalFunc {
+		return func(inputs chan int) int {
+			order = append(order, id)
+			return eval(inputs)
+		}
+	}
+	sum := NewNode("sum", record("sum", Sum))
+	max := NewNode("max", record("max", Max), sum)
+	min := NewNode("min", record("min", Min), sum)
+	graph, err := New(
+		NewNode("1", record("1", Constant(1)), max),
+		NewNode("2", record("2", Constant(2)), max),
+		NewNode("3", record("3", Constant(3)), min),
+		NewNode("4", record("4", Constant(4)), min),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectOrder, err := graph.evaluationOrder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.EvaluateDebug(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(order) != fmt.Sprint(nodeIDs(expectOrder)) {
+		t.Fatalf("unexpected evaluation order: want %v but got %v", nodeIDs(expectOrder), order)
+	}
+
+	expected, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := expected.Evaluate(3); err != nil {
+		t.Fatal(err)
+	}
+	for id, node := range expected {
+		if result := graph[id].Result; result != node.Result {
+			t.Fatalf("unexpected result for node %s: want %d but got %d", id, node.Result, result)
+		}
+	}
+}
+
+func TestEvaluateBatch(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := graph.EvaluateBatch([]map[string]int{
+		{},
+		{"1": 10},
+		{"3": 0, "4": -1},
+	}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []map[string]int{
+		{"1": 1, "2": 2, "3": 3, "4": 4, "max": 2, "min": 3, "sum": 5},
+		{"1": 10, "2": 2, "3": 3, "4": 4, "max": 10, "min": 3, "sum": 13},
+		{"1": 1, "2": 2, "3": 0, "4": -1, "max": 2, "min": -1, "sum": 1},
+	}
+	if fmt.Sprint(results) != fmt.Sprint(expect) {
+		t.Fatalf("unexpected results:\nwant %v\n got %v", expect, results)
+	}
+	if _, err := graph.Result("sum"); !errors.Is(err, ErrNotEvaluated) {
+		t.Fatalf("expected the original graph not to be evaluated but got %v", err)
+	}
+	if _, err := graph.EvaluateBatch([]map[string]int{{"nope": 1}}, 1); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+
+	// Every run evaluates the injected input, as EvaluateCopy does.
+	if err := graph.Inject("sum", 10); err != nil {
+		t.Fatal(err)
+	}
+	results, err = graph.EvaluateBatch([]map[string]int{{}, {"1": 10}}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied, err := graph.EvaluateCopy(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0]["sum"] != copied["sum"] || results[0]["sum"] != 15 || results[1]["sum"] != 23 {
+		t.Fatalf("expected the injected input in every run but got %v and %v", results[0]["sum"], results[1]["sum"])
+	}
+}
+
+func TestRejectEmpty(t *testing.T) {
+	graph, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(1); err != nil {
+		t.Fatalf("expected no error by default but got %v", err)
+	}
+	if err := graph.Evaluate(1, WithRejectEmpty()); !errors.Is(err, ErrEmptyGraph) {
+		t.Fatalf("expected ErrEmptyGraph but got %v", err)
+	}
+}
+
+func TestLevelConcurrency(t *testing.T) {
+	counter := &inFlightCounter{}
+	graph, err := counter.wideGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(8, WithLevelConcurrency(3)); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["sum"].Result; result != 28 {
+		t.Fatalf("unexpected result for node sum: want 28 but got %d", result)
+	}
+	if max := counter.max.Load(); max > 3 {
+		t.Fatalf("expected at most 3 nodes in flight but got %d", max)
+	}
+}
+
+func TestInputOrderCheck(t *testing.T) {
+	subtract := func(inputs chan int) int {
+		result := <-inputs
+		for input := range inputs {
+			result -= input
+		}
+		return result
+	}
+	newGraph := func(order ...string) Graph {
+		difference := NewNode("difference", subtract)
+		difference.InputOrder = order
+		a := NewNode("a", Constant(5), difference)
+		b := NewNode("b", Constant(3), difference)
+		b.Priority = 1
+		graph, err := New(a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return graph
+	}
+
+	// With a single worker, "b" is evaluated before "a" because of its Priority.
+	graph := newGraph("a", "b")
+	err := graph.Evaluate(1, WithInputOrderCheck())
+	if !errors.Is(err, ErrInputOrder) {
+		t.Fatalf("expected ErrInputOrder but got %v", err)
+	}
+	var nodeErr *NodeError
+	if !errors.As(err, &nodeErr) || nodeErr.ID != "difference" {
+		t.Fatalf("expected the error to name node difference but got %v", err)
+	}
+	if _, err := graph.Result("difference"); !errors.Is(err, ErrNotEvaluated) {
+		t.Fatalf("expected difference not to be evaluated but got %v", err)
+	}
+
+	graph = newGraph("b", "a")
+	if err := graph.Evaluate(1, WithInputOrderCheck()); err != nil {
+		t.Fatal(err)
+	}
+	if graph["difference"].Result != -2 {
+		t.Fatalf("expected -2 but got %d", graph["difference"].Result)
+	}
+}
+
+func TestPackFunc(t *testing.T) {
+	// "high" depends on "low", so the input of "low" always arrives first.
+	pack := NewEvaluatorNode("pack", PackFunc(func(ordered []int) int {
+		return ordered[0]<<8 | ordered[1]
+	}))
+	pack.InputOrder = []string{"high", "low"}
+	high := NewNode("high", Constant(0x12), pack)
+	graph, err := New(NewNode("low", Constant(0x34), pack, high))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if received := fmt.Sprint(pack.Inputs); received != "[52 18]" {
+		t.Fatalf("expected the inputs to arrive in reverse order but got %s", received)
+	}
+	if result := graph["pack"].Result; result != 0x1234 {
+		t.Fatalf("unexpected result: want %#x but got %#x", 0x1234, result)
+	}
+}
+
+// countEvaluations wraps the EvalFunc of every Node in the Graph to count how many times each Node is evaluated.
+// The counts must only be read while the Graph is not being evaluated.
+func countEvaluations(t *testing.T, graph Graph) map[string]int {
+	calls := map[string]int{}
+	mu := sync.Mutex{}
+	for id, n := range graph {
+		id, eval := id, n.eval
+		if err := graph.SetEval(id, func(inputs chan int) int {
+			mu.Lock()
+			calls[id]++
+			mu.Unlock()
+			result, _ := eval.Evaluate(EvalContext{Inputs: inputs})
+			return result
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return calls
+}
+
+func TestEvaluateIncremental(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := countEvaluations(t, graph)
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+
+	// Raise constant 1 above constant 2, which changes max and sum but not min.
+	if err := graph.SetEval("1", Constant(10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.EvaluateIncremental([]string{"1"}, 2); err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]int{"1": 10, "2": 2, "3": 3, "4": 4, "max": 10, "min": 3, "sum": 13} {
+		if result := graph[id].Result; result != want {
+			t.Fatalf("unexpected result for node %s: want %d but got %d", id, want, result)
+		}
+	}
+	for id, want := range map[string]int{"2": 1, "3": 1, "4": 1, "min": 1, "max": 2, "sum": 2} {
+		if calls[id] != want {
+			t.Fatalf("unexpected number of evaluations of node %s: want %d but got %d", id, want, calls[id])
+		}
+	}
+
+	if err := graph.EvaluateIncremental([]string{"nope"}, 2); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+}
+
+func TestEvaluateIncrementalInjected(t *testing.T) {
+	// "sum" has not consumed its injected input when the evaluation stops at it, so the incremental
+	// evaluation must give the same result as a full evaluation of the changed Graph.
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Inject("sum", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.EvaluatePartial([]string{"sum"}, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.SetEval("1", Constant(5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.EvaluateIncremental([]string{"1"}, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	full, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := full.SetEval("1", Constant(5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := full.Inject("sum", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := full.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if incremental, want := graph["sum"].Result, full["sum"].Result; incremental != want || want != 18 {
+		t.Fatalf("unexpected result for node sum: want %d from a full evaluation but got %d", want, incremental)
+	}
+}
+
+func TestEvaluateNode(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := countEvaluations(t, graph)
+
+	result, err := graph.EvaluateNode("max", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != 2 {
+		t.Fatalf("unexpected result for node max: want 2 but got %d", result)
+	}
+	for _, id := range []string{"3", "4", "min", "sum"} {
+		if calls[id] != 0 {
+			t.Fatalf("expected node %s not to be evaluated", id)
+		}
+	}
+	if _, err := graph.Result("max"); !errors.Is(err, ErrNotEvaluated) {
+		t.Fatalf("expected the graph not to be evaluated but got %v", err)
+	}
+
+	if _, err := graph.EvaluateNode("nope", 2); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+}
+
+func TestEvaluateNodeInjected(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Inject("sum", 10); err != nil {
+		t.Fatal(err)
+	}
+	result, err := graph.EvaluateNode("sum", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != 15 {
+		t.Fatalf("unexpected result for node sum: want 15 but got %d", result)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["sum"].Result; result != 15 {
+		t.Fatalf("expected the graph to keep its injected input: want 15 but got %d", result)
+	}
+}
+
+func TestArgMax(t *testing.T) {
+	for _, test := range []struct {
+		Name   string
+		Eval   OrderedFunc
+		Expect string
+	}{
+		{Name: "max", Eval: ArgMax, Expect: "a"},
+		{Name: "min", Eval: ArgMin, Expect: "a"},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			// "a" depends on "b", so the input of "b" always arrives first.
+			winner := NewEvaluatorNode("winner", test.Eval)
+			winner.InputOrder = []string{"a", "b"}
+			a := NewNode("a", Constant(7), winner)
+			graph, err := New(NewNode("b", Constant(7), winner, a))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := graph.Evaluate(2); err != nil {
+				t.Fatal(err)
+			}
+			if source := winner.InputOrder[winner.Result]; source != test.Expect {
+				t.Fatalf("expected the tie to be won by %s but got %s", test.Expect, source)
+			}
+		})
+	}
+
+	if result := ArgMax([]int{1, 3, 2}); result != 1 {
+		t.Fatalf("unexpected ArgMax: want 1 but got %d", result)
+	}
+	if result := ArgMin([]int{3, 1, 2}); result != 1 {
+		t.Fatalf("unexpected ArgMin: want 1 but got %d", result)
+	}
+	if result := ArgMax(nil); result != -1 {
+		t.Fatalf("unexpected ArgMax without inputs: want -1 but got %d", result)
+	}
+}
+
+func TestPassthrough(t *testing.T) {
+	relay := NewNode("relay", Passthrough,
+		NewNode("a", Sum), NewNode("b", Max), NewNode("c", Min))
+	graph, err := New(NewNode("source", Constant(42), relay))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"relay", "a", "b", "c"} {
+		if result := graph[id].Result; result != 42 {
+			t.Fatalf("unexpected result for node %s: want 42 but got %d", id, result)
+		}
+	}
+	if result := Passthrough(inputs()); result != 0 {
+		t.Fatalf("unexpected result without inputs: want 0 but got %d", result)
+	}
+}
+
+func TestEvaluationStartedLog(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	started := regexp.MustCompile(`evaluation started: .* levels=(.*)`).FindStringSubmatch(out.String())
+	if started == nil {
+		t.Fatalf("expected an evaluation started log line but got:\n%s", out)
+	}
+	if expect := "0:[1 2 3 4] 1:[max min] 2:[sum]"; started[1] != expect {
+		t.Fatalf("unexpected levels: want %q but got %q", expect, started[1])
+	}
+}
+
+func TestReduceIndexed(t *testing.T) {
+	// A running mean multiplies the previous mean back out by the number of inputs before dividing again.
+	mean := ReduceIndexed(0, func(acc, in, index int) int {
+		return (acc*index + in) / (index + 1)
+	})
+	if result := mean(inputs(2, 4, 6, 8)); result != 5 {
+		t.Fatalf("unexpected mean: want 5 but got %d", result)
+	}
+	if result := mean(inputs()); result != 0 {
+		t.Fatalf("unexpected mean without inputs: want 0 but got %d", result)
+	}
+	count := ReduceIndexed(-1, func(acc, in, index int) int { return index + 1 })
+	if result := count(inputs(7, 7, 7)); result != 3 {
+		t.Fatalf("unexpected count: want 3 but got %d", result)
+	}
+}
+
+func TestMustEvaluate(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph.MustEvaluate(2)
+	if result := graph["sum"].Result; result != 5 {
+		t.Fatalf("unexpected result for node sum: want 5 but got %d", result)
+	}
+
+	errBoom := errors.New("boom")
+	graph, err = New(NewEvaluatorNode("fail", failing{errBoom}, NewNode("max", Max)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		r := recover()
+		if err, ok := r.(error); !ok || !errors.Is(err, errBoom) {
+			t.Fatalf("expected a panic with the node's error but got %v", r)
+		}
+	}()
+	graph.MustEvaluate(2)
+	t.Fatal("expected MustEvaluate to panic")
+}
+
+func TestFanInAboveMaxIndegree(t *testing.T) {
+	// Each parent sends its input before the Node is evaluated, so with a buffer smaller than the
+	// fan-in the last parent used to block the only worker forever.
+	sum := NewNode("sum", Sum)
+	heads := make([]*Node, MaxIndegree+1)
+	for i := range heads {
+		heads[i] = NewNode(fmt.Sprint(i), Constant(1), sum)
+	}
+	graph, err := New(heads...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evaluated := make(chan error, 1)
+	go func() {
+		evaluated <- graph.Evaluate(1)
+	}()
+	select {
+	case err := <-evaluated:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("evaluation deadlocked")
+	}
+	if result := graph["sum"].Result; result != len(heads) {
+		t.Fatalf("unexpected result for node sum: want %d but got %d", len(heads), result)
+	}
+}
+
+func TestStreamingGoroutines(t *testing.T) {
+	// The goroutine discarding a Streaming Node's unconsumed inputs must exit after every evaluation.
+	before := runtime.NumGoroutine()
+	for run := 0; run < 50; run++ {
+		sum := NewNode("sum", Sum)
+		sum.Streaming = true
+		graph, err := New(NewNode("1", Constant(1), sum), NewNode("2", Constant(2), sum))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := graph.Evaluate(2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Fatalf("expected no leaked goroutines but went from %d to %d", before, after)
+	}
+}
+
+func TestStreamingFanInAboveMaxIndegree(t *testing.T) {
+	for _, concurrency := range []int{1, 2} {
+		sum := NewNode("sum", Sum)
+		sum.Streaming = true
+		heads := make([]*Node, MaxIndegree+2)
+		for i := range heads {
+			heads[i] = NewNode(fmt.Sprint(i), Constant(1), sum)
+		}
+		graph, err := New(heads...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		evaluated := make(chan error, 1)
+		go func() {
+			evaluated <- graph.Evaluate(concurrency)
+		}()
+		select {
+		case err := <-evaluated:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("evaluation with concurrency %d deadlocked", concurrency)
+		}
+		if result := graph["sum"].Result; result != len(heads) {
+			t.Fatalf("unexpected result for node sum: want %d but got %d", len(heads), result)
+		}
+	}
+}
+
+func TestEvaluateCopy(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for run := 0; run < 2; run++ {
+		results, err := graph.EvaluateCopy(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for id, want := range map[string]int{"1": 1, "4": 4, "max": 2, "min": 3, "sum": 5} {
+			if results[id] != want {
+				t.Fatalf("run %d: unexpected result for node %s: want %d but got %d", run, id, want, results[id])
+			}
+		}
+	}
+	if _, err := graph.Result("sum"); !errors.Is(err, ErrNotEvaluated) {
+		t.Fatalf("expected the graph not to be evaluated but got %v", err)
+	}
+}
+
+func TestEvaluateCopyInjected(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Inject("sum", 10); err != nil {
+		t.Fatal(err)
+	}
+	results, err := graph.EvaluateCopy(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results["sum"] != 15 {
+		t.Fatalf("unexpected result for node sum in the copy: want 15 but got %d", results["sum"])
+	}
+	// The original still holds its injected input, which a copy made after it was consumed does not repeat.
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["sum"].Result; result != 15 {
+		t.Fatalf("unexpected result for node sum: want 15 but got %d", result)
+	}
+	results, err = graph.EvaluateCopy(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results["sum"] != 5 {
+		t.Fatalf("unexpected result for node sum in a copy of the evaluated graph: want 5 but got %d", results["sum"])
+	}
+}
+
+func TestWeightedAverage(t *testing.T) {
+	// "10" depends on "30", so the input of "30" always arrives first.
+	average := NewEvaluatorNode("average", WeightedAverage(1, 3))
+	average.InputOrder = []string{"10", "30"}
+	ten := NewNode("10", Constant(10), average)
+	graph, err := New(NewNode("30", Constant(30), average, ten))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if received := fmt.Sprint(average.Inputs); received != "[30 10]" {
+		t.Fatalf("expected the inputs to arrive in reverse order but got %s", received)
+	}
+	if result := graph["average"].Result; result != 25 {
+		t.Fatalf("unexpected weighted average: want 25 but got %d", result)
+	}
+
+	if result := WeightedAverage(1, 3)(nil); result != 0 {
+		t.Fatalf("unexpected result without inputs: want 0 but got %d", result)
+	}
+	if result := WeightedAverage(0, 0)([]int{10, 30}); result != 0 {
+		t.Fatalf("unexpected result with zero total weight: want 0 but got %d", result)
+	}
+}
+
+func TestEvaluatePartial(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := countEvaluations(t, graph)
+	if err := graph.EvaluatePartial([]string{"nope"}, 2); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+	if err := graph.EvaluatePartial([]string{"sum"}, 2); err != nil {
+		t.Fatal(err)
+	}
+	for id, expect := range map[string]int{"max": 2, "min": 3} {
+		if result, err := graph.Result(id); err != nil || result != expect {
+			t.Fatalf("unexpected result for node %s: want %d but got %d (%v)", id, expect, result, err)
+		}
+	}
+	if _, err := graph.Result("sum"); !errors.Is(err, ErrNotEvaluated) {
+		t.Fatalf("expected sum not to be evaluated at the barrier but got %v", err)
+	}
+
+	if err := graph.Resume(2); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := graph.Result("sum"); err != nil || result != 5 {
+		t.Fatalf("unexpected result for node sum: want 5 but got %d (%v)", result, err)
+	}
+	for id := range graph {
+		if calls[id] != 1 {
+			t.Fatalf("expected node %s to be evaluated once but got %d", id, calls[id])
+		}
+	}
+}
+
+func TestResumeAfterFailure(t *testing.T) {
+	errBoom := errors.New("boom")
+	sum := NewNode("sum", Sum)
+	graph, err := New(NewEvaluatorNode("fail", failing{errBoom}, NewNode("max", Max, sum)), NewNode("1", Constant(1), sum))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.EvaluatePartial([]string{"sum"}, 2); !errors.Is(err, errBoom) {
+		t.Fatalf("expected errBoom but got %v", err)
+	}
+	if err := graph.Resume(2); !errors.Is(err, ErrNotResumable) {
+		t.Fatalf("expected ErrNotResumable after a failed partial evaluation but got %v", err)
+	}
+	if _, err := graph.Result("sum"); !errors.Is(err, ErrNotEvaluated) {
+		t.Fatalf("expected sum not to be evaluated but got %v", err)
+	}
+
+	// Resuming after a failed full evaluation doesn't evaluate any Node again.
+	graph, err = New(NewEvaluatorNode("fail", failing{errBoom}, NewNode("max", Max)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); !errors.Is(err, errBoom) {
+		t.Fatalf("expected errBoom but got %v", err)
+	}
+	if err := graph.Resume(2); !errors.Is(err, ErrNotResumable) {
+		t.Fatalf("expected ErrNotResumable after a failed evaluation but got %v", err)
+	}
+}
+
+func TestInputTransform(t *testing.T) {
+	sum := NewNode("sum", Sum)
+	sum.InputTransform = func(inputs []int) []int {
+		kept := inputs[:0]
+		for _, input := range inputs {
+			if input >= 0 {
+				kept = append(kept, input)
+			}
+		}
+		return kept
+	}
+	graph, err := New(NewNode("a", Constant(5), sum), NewNode("b", Constant(-3), sum), NewNode("c", Constant(2), sum))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["sum"].Result; result != 7 {
+		t.Fatalf("unexpected result for node sum: want 7 but got %d", result)
+	}
+	if len(graph["sum"].Inputs) != 3 {
+		t.Fatalf("expected Inputs to record every input but got %v", graph["sum"].Inputs)
+	}
+}
+
+func TestStarvedNodes(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if starved := graph.StarvedNodes(); len(starved) != 0 {
+		t.Fatalf("expected no starved nodes in a correctly wired graph but got %v", nodeIDs(starved))
+	}
+
+	// A Streaming Node does not wait for its parents, so it still runs when its only parent fails.
+	stream := NewNode("stream", Sum)
+	stream.Streaming = true
+	errBoom := errors.New("boom")
+	graph, err = New(NewEvaluatorNode("fail", failing{errBoom}, stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); !errors.Is(err, errBoom) {
+		t.Fatalf("expected errBoom but got %v", err)
+	}
+	if ids := fmt.Sprint(nodeIDs(graph.StarvedNodes())); ids != "[stream]" {
+		t.Fatalf("unexpected starved nodes: want [stream] but got %s", ids)
+	}
+}
+
+func TestEvaluateWithInputsLog(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := graph.EvaluateWithInputsLog(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logged := map[EdgeValue]bool{}
+	for _, value := range values {
+		logged[value] = true
+	}
+	expect := []EdgeValue{
+		{From: "1", To: "max", Value: 1},
+		{From: "2", To: "max", Value: 2},
+		{From: "3", To: "min", Value: 3},
+		{From: "4", To: "min", Value: 4},
+		{From: "max", To: "sum", Value: 2},
+		{From: "min", To: "sum", Value: 3},
+	}
+	if len(values) != len(expect) {
+		t.Fatalf("expected %d edge values but got %v", len(expect), values)
+	}
+	for _, value := range expect {
+		if !logged[value] {
+			t.Fatalf("expected the log to contain %+v but got %v", value, values)
+		}
+	}
+}
+
+func TestOutputValidator(t *testing.T) {
+	errTooLarge := errors.New("result too large")
+	product := NewNode("product", func(inputs chan int) int {
+		return Collect(inputs)[0] * 4
+	}, NewNode("next", Sum))
+	product.OutputValidator = func(result int) error {
+		if result > 10 {
+			return errTooLarge
+		}
+		return nil
+	}
+	graph, err := New(NewNode("6", Constant(6), product))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = graph.Evaluate(2)
+	var nodeErr *NodeError
+	if !errors.Is(err, errTooLarge) || !errors.As(err, &nodeErr) || nodeErr.ID != "product" {
+		t.Fatalf("expected node product to fail validation but got %v", err)
+	}
+	for _, id := range []string{"product", "next"} {
+		if _, err := graph.Result(id); !errors.Is(err, ErrNotEvaluated) {
+			t.Fatalf("expected node %s not to be evaluated but got %v", id, err)
+		}
+	}
+}
+
+func TestParallelReduce(t *testing.T) {
+	add := func(acc, in int) int { return acc + in }
+	values := make([]int, 10007)
+	for i := range values {
+		values[i] = i - 5000
+	}
+	for _, chunk := range []int{0, 1, 100, 3333, 20000} {
+		if result, expect := ParallelReduce(0, add, chunk)(inputs(values...)), Sum(inputs(values...)); result != expect {
+			t.Fatalf("unexpected sum with chunks of %d: want %d but got %d", chunk, expect, result)
+		}
+		if result, expect := ParallelReduce(math.MaxInt, func(acc, in int) int {
+			if in < acc {
+				return in
+			}
+			return acc
+		}, chunk)(inputs(values...)), Min(inputs(values...)); result != expect {
+			t.Fatalf("unexpected min with chunks of %d: want %d but got %d", chunk, expect, result)
+		}
+	}
+	if result := ParallelReduce(7, add, 10)(inputs()); result != 7 {
+		t.Fatalf("unexpected result without inputs: want 7 but got %d", result)
+	}
+}
+
+func BenchmarkParallelReduce(b *testing.B) {
+	const size = 1_000_000
+	buffered := func() chan int {
+		ch := make(chan int, size)
+		for i := 0; i < size; i++ {
+			ch <- i
+		}
+		close(ch)
+		return ch
+	}
+	for _, bench := range []struct {
+		Name string
+		Eval EvalFunc
+	}{
+		{Name: "serial", Eval: Sum},
+		{Name: "parallel", Eval: ParallelReduce(0, func(acc, in int) int { return acc + in }, size/runtime.NumCPU())},
+	} {
+		b.Run(bench.Name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				ch := buffered()
+				b.StartTimer()
+				bench.Eval(ch)
+			}
+		})
+	}
+}
+
+func TestWithSkip(t *testing.T) {
+	// "double" depends only on "max", so it is skipped with it, while "sum" still has "min".
+	sum := NewNode("sum", Sum)
+	double := NewNode("double", func(inputs chan int) int { return 2 * Sum(inputs) })
+	max := NewNode("max", Max, sum, double)
+	min := NewNode("min", Min, sum)
+	graph, err := New(
+		NewNode("1", Constant(1), max), NewNode("2", Constant(2), max),
+		NewNode("3", Constant(3), min), NewNode("4", Constant(4), min))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := countEvaluations(t, graph)
+	if err := graph.Evaluate(2, WithSkip("max")); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"max", "double"} {
+		if calls[id] != 0 {
+			t.Fatalf("expected node %s not to run but it ran %d times", id, calls[id])
+		}
+		if _, err := graph.Result(id); !errors.Is(err, ErrNotEvaluated) {
+			t.Fatalf("expected node %s not to be evaluated but got %v", id, err)
+		}
+	}
+	for _, id := range []string{"1", "2", "3", "4", "min", "sum"} {
+		if calls[id] != 1 {
+			t.Fatalf("expected node %s to run once but it ran %d times", id, calls[id])
+		}
+	}
+	if result := graph["sum"].Result; result != 3 {
+		t.Fatalf("unexpected result for node sum: want 3 but got %d", result)
+	}
+
+	graph, err = assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2, WithSkip("nope")); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+}
+
+func TestWithSkipEntryPoints(t *testing.T) {
+	evaluations := map[string]func(Graph) error{
+		"EvaluateDebug": func(g Graph) error { return g.EvaluateDebug(WithSkip("max")) },
+		"EvaluateIncremental": func(g Graph) error {
+			return g.EvaluateIncremental([]string{"1", "2", "3", "4"}, 2, WithSkip("max"))
+		},
+		"EvaluatePartial": func(g Graph) error { return g.EvaluatePartial([]string{"sum"}, 2, WithSkip("max")) },
+		"Resume": func(g Graph) error {
+			if err := g.EvaluatePartial([]string{"max"}, 2); err != nil {
+				return err
+			}
+			return g.Resume(2, WithSkip("max"))
+		},
+	}
+	for name, evaluate := range evaluations {
+		t.Run(name, func(t *testing.T) {
+			graph, err := assignmentGraph()
+			if err != nil {
+				t.Fatal(err)
+			}
+			calls := countEvaluations(t, graph)
+			if err := evaluate(graph); err != nil {
+				t.Fatal(err)
+			}
+			if calls["max"] != 0 {
+				t.Fatalf("expected node max not to run but it ran %d times", calls["max"])
+			}
+			if calls["min"] != 1 {
+				t.Fatalf("expected node min to run once but it ran %d times", calls["min"])
+			}
+		})
+	}
+}
+
+func TestWithSkipInputOrderCheck(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph["sum"].InputOrder = []string{"max", "min"}
+	if err := graph.Evaluate(1, WithSkip("max"), WithInputOrderCheck()); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["sum"].Result; result != 3 {
+		t.Fatalf("unexpected result for node sum: want 3 but got %d", result)
+	}
+}
+
+func TestReducerDefaults(t *testing.T) {
+	for _, test := range []struct {
+		Name   string
+		Eval   EvalFunc
+		Inputs []int
+		Expect int
+	}{
+		{Name: "max empty", Eval: MaxOr(math.MinInt), Expect: math.MinInt},
+		{Name: "max negative", Eval: MaxOr(math.MinInt), Inputs: []int{-5, -2, -9}, Expect: -2},
+		{Name: "min empty", Eval: MinOr(math.MaxInt), Expect: math.MaxInt},
+		{Name: "min", Eval: MinOr(math.MaxInt), Inputs: []int{5, 2, 9}, Expect: 2},
+		{Name: "sum empty", Eval: SumOr(-1), Expect: -1},
+		{Name: "sum", Eval: SumOr(-1), Inputs: []int{5, 2, 9}, Expect: 16},
+	} {
+		if result := test.Eval(inputs(test.Inputs...)); result != test.Expect {
+			t.Fatalf("unexpected result for %s: want %d but got %d", test.Name, test.Expect, result)
+		}
+	}
+}
+
+func TestMapResults(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	graph.MapResults(func(_ string, r int) int { return r * 2 })
+	for id, want := range map[string]int{"1": 2, "2": 4, "3": 6, "4": 8, "max": 4, "min": 6, "sum": 10} {
+		if result := graph[id].Result; result != want {
+			t.Fatalf("unexpected result for node %s: want %d but got %d", id, want, result)
+		}
+		if result := graph[id].AnyResult; result != want {
+			t.Fatalf("unexpected AnyResult for node %s: want %d but got %v", id, want, result)
+		}
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Make sum wait for an input from a parent that doesn't exist.
+	graph["sum"].indegree++
+	graph["sum"].wait.Add(1)
+
+	evaluated := make(chan error, 1)
+	go func() {
+		evaluated <- graph.Evaluate(2, WithWatchdog(50*time.Millisecond))
+	}()
+	select {
+	case err := <-evaluated:
+		if !errors.Is(err, ErrDeadlockSuspected) {
+			t.Fatalf("expected ErrDeadlockSuspected but got %v", err)
+		}
+		if !strings.Contains(err.Error(), "sum (1 of 3 inputs remaining)") {
+			t.Fatalf("expected the error to describe node sum but got %q", err)
+		}
+		if strings.Contains(err.Error(), "max") {
+			t.Fatalf("expected completed nodes not to be listed but got %q", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the watchdog did not fire")
+	}
+}
+
+func TestNodeInputs(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	received := append([]int(nil), graph["sum"].Inputs...)
+	sort.Ints(received)
+	if fmt.Sprint(received) != "[2 3]" {
+		t.Fatalf("expected sum to receive the results of max and min but got %v", graph["sum"].Inputs)
+	}
+	if len(graph["1"].Inputs) != 0 {
+		t.Fatalf("expected root 1 to receive no inputs but got %v", graph["1"].Inputs)
+	}
+}
+
+func TestGoroutineLauncher(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	launched := atomic.Int32{}
+	err = graph.Evaluate(3, WithGoroutineLauncher(func(fn func()) {
+		launched.Add(1)
+		go fn()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := launched.Load(); n != 3 {
+		t.Fatalf("expected the launcher to be called 3 times but got %d", n)
+	}
+	if result := graph["sum"].Result; result != 5 {
+		t.Fatalf("unexpected result for node sum: want 5 but got %d", result)
+	}
+}
+
+func TestProductChecked(t *testing.T) {
+	if _, err := ProductChecked(inputs(math.MaxInt, 2)); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow but got %v", err)
+	}
+	if _, err := ProductChecked(inputs(math.MinInt, -1)); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow for the sign change but got %v", err)
+	}
+	if result, err := ProductChecked(inputs()); err != nil || result != 1 {
+		t.Fatalf("unexpected empty product: want 1 but got %d (%v)", result, err)
+	}
+	if result, err := ProductChecked(inputs(-3, 4, 5)); err != nil || result != -60 {
+		t.Fatalf("unexpected product: want -60 but got %d (%v)", result, err)
+	}
+
+	product := NewEvaluatorNode("product", EvalFuncWithError(ProductChecked))
+	graph, err := New(NewNode("max", Constant(math.MaxInt), product), NewNode("2", Constant(2), product))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nodeErr *NodeError
+	if err := graph.Evaluate(2); !errors.Is(err, ErrOverflow) || !errors.As(err, &nodeErr) || nodeErr.ID != "product" {
+		t.Fatalf("expected node product to fail with ErrOverflow but got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package dag
+
+import (
+	"context"
+	"sort"
+)
+
+// Evaluator computes the result of a Node from its inputs.
+// It is a more general form of EvalFunc, with access to the Node's identity and a context.
+type Evaluator interface {
+	Evaluate(EvalContext) (int, error)
+}
+
+// EvalContext is passed to an Evaluator when its Node is evaluated.
+type EvalContext struct {
+	// Context is canceled when the Node's evaluation is abandoned, for example by WithNodeTimeout.
+	Context context.Context
+	// NodeID is the ID of the Node being evaluated.
+	NodeID string
+	// Inputs carries the results of the Node's parents, as described for EvalFunc.
+	Inputs chan int
+	// Metadata is the Metadata of the Node being evaluated.
+	Metadata map[string]string
+
+	// anyInputs carries the inputs of a Node with an EvalFuncAny.
+	anyInputs chan any
+	// sources holds the ID of the parent that sent each input, with an empty ID for injected inputs,
+	// or nil if the inputs can't be matched to their parents.
+	sources []string
+	// inputOrder is the InputOrder of the Node being evaluated.
+	inputOrder []string
+}
+
+// Evaluate calls the EvalFunc with the inputs from the EvalContext. It never returns an error.
+func (f EvalFunc) Evaluate(ec EvalContext) (int, error) {
+	return f(ec.Inputs), nil
+}
+
+// EvalFuncWithError is a form of EvalFunc that can fail. It implements Evaluator,
+// so it can be used with NewEvaluatorNode.
+type EvalFuncWithError func(chan int) (int, error)
+
+// Evaluate calls the EvalFuncWithError with the inputs from the EvalContext.
+func (f EvalFuncWithError) Evaluate(ec EvalContext) (int, error) {
+	return f(ec.Inputs)
+}
+
+// OrderedFunc is an Evaluator that receives every input of its Node at once, ordered by the parent that sent it
+// instead of by arrival, so that positional reducers do not depend on which parent finishes first. The inputs of
+// the parents listed in the Node's InputOrder come first, in that order, followed by injected inputs in the order
+// they were injected and by the inputs of the other parents sorted by ID. The inputs of a Streaming Node or of a
+// Node with an InputTransform can't be matched to their parents and are passed in the order they are received.
+type OrderedFunc func(ordered []int) int
+
+// Evaluate collects the inputs from the EvalContext in order and calls the OrderedFunc. It never returns an error.
+func (f OrderedFunc) Evaluate(ec EvalContext) (int, error) {
+	return f(ec.ordered()), nil
+}
+
+// ordered collects the inputs from the EvalContext, ordered as described for OrderedFunc.
+func (ec EvalContext) ordered() []int {
+	values := Collect(ec.Inputs)
+	if len(ec.sources) != len(values) {
+		return values
+	}
+	rank := make(map[string]int, len(ec.inputOrder))
+	for i, id := range ec.inputOrder {
+		rank[id] = i
+	}
+	key := func(id string) int {
+		if r, ok := rank[id]; ok && id != "" {
+			return r
+		}
+		return len(ec.inputOrder)
+	}
+	positions := make([]int, len(values))
+	for i := range positions {
+		positions[i] = i
+	}
+	sort.SliceStable(positions, func(a, b int) bool {
+		sa, sb := ec.sources[positions[a]], ec.sources[positions[b]]
+		if ka, kb := key(sa), key(sb); ka != kb {
+			return ka < kb
+		}
+		return sa < sb
+	})
+	ordered := make([]int, len(values))
+	for i, position := range positions {
+		ordered[i] = values[position]
+	}
+	return ordered
+}
+
+// Named returns an Evaluator that evaluates like eval but is identified by the given name, such as "const:5".
+// Evaluators can't be compared, so Normalize only merges Nodes whose Evaluators are Named with the same name;
+// the caller guarantees that Evaluators with the same name compute the same result from the same inputs.
+func Named(name string, eval Evaluator) Evaluator {
+	return namedEvaluator{name: name, Evaluator: eval}
+}
+
+// namedEvaluator is an Evaluator identified by a name for Normalize.
+type namedEvaluator struct {
+	name string
+	Evaluator
+}
+
+// Idempotent returns an Evaluator that evaluates like eval and declares that its result does not change when
+// one of its inputs is repeated, as for Max and Min but not for Sum. Normalize only merges Nodes whose Next Nodes
+// all have Idempotent Evaluators, since each of them receives the merged input once instead of once per duplicate.
+func Idempotent(eval Evaluator) Evaluator {
+	return idempotentEvaluator{eval}
+}
+
+// idempotentEvaluator is an Evaluator declared Idempotent for Normalize.
+type idempotentEvaluator struct {
+	Evaluator
+}
+
+// isIdempotent reports whether the Evaluator, or the Evaluator it names, is Idempotent.
+func isIdempotent(eval Evaluator) bool {
+	if named, ok := eval.(namedEvaluator); ok {
+		eval = named.Evaluator
+	}
+	_, ok := eval.(idempotentEvaluator)
+	return ok
+}
+
+// NewEvaluatorNode returns a Node with the given ID and Evaluator, like NewNode.
+// If the Evaluator returns an error, the Node fails and its descendants are not evaluated.
+func NewEvaluatorNode(id string, eval Evaluator, next ...*Node) *Node {
+	n := newNode(id, eval)
+	n.connectAll(next)
+	return n
+}
@@ -0,0 +1,52 @@
+package dag
+
+import (
+	"errors"
+	"testing"
+)
+
+// idLength is an Evaluator that returns the length of its Node's ID.
+type idLength struct{}
+
+func (idLength) Evaluate(ec EvalContext) (int, error) {
+	return len(ec.NodeID), nil
+}
+
+// failing is an Evaluator that always returns its error.
+type failing struct{ err error }
+
+func (f failing) Evaluate(EvalContext) (int, error) {
+	return 0, f.err
+}
+
+func TestEvaluator(t *testing.T) {
+	graph, err := New(NewEvaluatorNode("seven", idLength{}, NewNode("max", Max)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["max"].Result; result != 5 {
+		t.Fatalf("unexpected result for node max: want 5 but got %d", result)
+	}
+}
+
+func TestEvaluatorError(t *testing.T) {
+	errBoom := errors.New("boom")
+	graph, err := New(NewEvaluatorNode("fail", failing{errBoom}, NewNode("max", Max)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = graph.Evaluate(2)
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected the evaluator's error but got %v", err)
+	}
+	var nodeErr *NodeError
+	if !errors.As(err, &nodeErr) || nodeErr.ID != "fail" {
+		t.Fatalf("expected a NodeError for node fail but got %v", err)
+	}
+	if _, err := graph.Result("max"); !errors.Is(err, ErrNotEvaluated) {
+		t.Fatalf("expected node max not to be evaluated but got %v", err)
+	}
+}
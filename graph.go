@@ -2,42 +2,162 @@ package dag
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Node is a single computation step in a Graph.
 // To construct Nodes, use the NewNode function.
+// Metadata holds arbitrary caller-defined annotations and has no effect on evaluation.
+// When several Nodes are ready to be evaluated, Nodes with a higher Priority are scheduled first.
+// A Streaming Node runs its EvalFunc without waiting for its parents; see EvalFunc.
+// Cost is an estimate of the Node's evaluation cost used by CriticalPath, and defaults to 1.
+// InputOrder marks the EvalFunc as non-commutative by listing the IDs of its parents in the order
+// it expects their inputs; see OrderedFunc and WithInputOrderCheck.
+// AnyResult holds the result of a Node with an EvalFuncAny, or its Result for other Nodes.
+// Inputs records the inputs the Node received during evaluation, in the order they were received,
+// for inspection after evaluation.
+// InputTransform, if set, preprocesses the Node's inputs before its EvalFunc sees them, for example to clamp
+// or filter them. It is not applied to Streaming Nodes or Nodes with an EvalFuncAny, and does not change Inputs.
+// OutputValidator, if set, checks the Node's Result after its EvalFunc returns. If it returns an error,
+// the Node fails as if its Evaluator had returned the error, so its descendants are not evaluated.
 type Node struct {
-	ID       string
-	Next     []*Node
-	Result   int
-	eval     EvalFunc
+	ID         string
+	Next       []*Node
+	Result     int
+	Metadata   map[string]string
+	Priority   int
+	Streaming  bool
+	Cost       int
+	InputOrder []string
+	AnyResult  any
+	Inputs     []int
+
+	InputTransform  func([]int) []int
+	OutputValidator func(int) error
+
+	eval     Evaluator
 	wait     *sync.WaitGroup
 	indegree int
+	injected int
 	inputs   chan int
+
+	// cache is the structureCache shared by every Node of the Graph.
+	cache *structureCache
+	// busy is the time spent running the Evaluator in the last evaluation.
+	busy time.Duration
+	// anyInputs carries the inputs of a Node with an EvalFuncAny instead of inputs, and is nil for other Nodes.
+	anyInputs chan any
+
+	// arrivals records the IDs of the parents in the order their inputs were sent.
+	arrivals []string
+	// receiveMu guards Inputs and arrivals while inputs are received.
+	receiveMu sync.Mutex
+
+	// parentFailed is set when a parent fails to produce a result during evaluation.
+	parentFailed atomic.Bool
+	// evaluated is set after Result is assigned during evaluation.
+	evaluated atomic.Bool
+	// completed is set when SetResult claims the Node, so that concurrent calls complete it only once.
+	completed atomic.Bool
+	// started is set when the Node is taken for evaluation or computed, after which its inputs are closed.
+	started atomic.Bool
+	// failed is set when the Node fails or is skipped because a parent failed during evaluation.
+	failed atomic.Bool
+	// received counts the parents that have sent an input or failed during evaluation.
+	received atomic.Int32
+
+	// countVisits is set by CountVisits to count the visits to the Node in visits.
+	countVisits atomic.Bool
+	visits      atomic.Int64
 }
 
 // NewNode returns a Node with the given ID and EvalFunc.
 // The Node's output will be sent to any Nodes provided as the "next" argument.
+// A Node provided more than once is only connected once.
 func NewNode(id string, eval EvalFunc, next ...*Node) *Node {
+	n := newNode(id, eval)
+	n.connectAll(next)
+	return n
+}
+
+// connectAll connects the Node to each of the given Nodes, skipping any it is already connected to.
+func (n *Node) connectAll(next []*Node) {
 	for _, next := range next {
-		next.wait.Add(1)
-		next.indegree++
+		if n.hasEdgeTo(next) {
+			log.Printf("duplicate edge: node %s is already connected to node %s", n.ID, next.ID)
+			continue
+		}
+		n.connect(next)
+	}
+}
+
+// hasEdgeTo reports whether the given Node is one of the Node's Next Nodes.
+func (n *Node) hasEdgeTo(next *Node) bool {
+	for _, existing := range n.Next {
+		if existing == next {
+			return true
+		}
 	}
-	return &Node{
+	return false
+}
+
+// newNode returns a Node with the given ID and Evaluator and no edges.
+func newNode(id string, eval Evaluator) *Node {
+	n := &Node{
 		ID:     id,
-		Next:   next,
+		Cost:   1,
 		eval:   eval,
 		wait:   &sync.WaitGroup{},
 		inputs: make(chan int, MaxIndegree),
 	}
+	if _, ok := eval.(anyEvaluator); ok {
+		n.anyInputs = make(chan any, MaxIndegree)
+	}
+	return n
+}
+
+// copyWithoutEdges returns a new Node with the same ID, Evaluator, and attributes as the Node, but no edges.
+// Inputs injected with Inject are copied too if the Node has not consumed them yet.
+func (n *Node) copyWithoutEdges() *Node {
+	out := newNode(n.ID, n.eval)
+	out.Metadata = copyMetadata(n.Metadata)
+	out.Priority = n.Priority
+	out.Streaming = n.Streaming
+	out.Cost = n.Cost
+	out.InputOrder = append([]string(nil), n.InputOrder...)
+	out.InputTransform = n.InputTransform
+	out.OutputValidator = n.OutputValidator
+	for _, value := range n.pendingInjected() {
+		out.inject(value)
+	}
+	return out
+}
+
+// connect adds an edge from the Node to the next Node, which will wait for one more input.
+func (n *Node) connect(next *Node) {
+	n.Next = append(n.Next, next)
+	next.wait.Add(1)
+	next.indegree++
 }
 
 // MaxIndegree sets the buffer size of the Inputs channel for Nodes.
+// When a Graph is evaluated or an input is injected, the buffer of a Node is enlarged if needed to hold an input
+// from every parent and every injected input, so that a parent never blocks a worker by sending to a Node that
+// is not consuming yet. Only WithMaxBufferedInputs limits the buffer below that.
 var MaxIndegree = 10
 
 // EvalFunc accepts a channel of zero or more numerical inputs and returns a single numerical output.
+// By default the channel is closed before the EvalFunc is called, so every input is already buffered.
+// For a Streaming Node, the EvalFunc is called as soon as the Node is scheduled and receives each input
+// as it is produced; the channel is closed once every parent has been evaluated. Successors receive the
+// output as soon as the EvalFunc returns, even if some parents have not been evaluated yet.
+// Inputs arrive in the order the parents finish, which is not guaranteed, so an EvalFunc should be
+// commutative. A non-commutative EvalFunc, such as subtraction, can declare its Node's InputOrder.
 type EvalFunc func(chan int) int
 
 // Graph is a directed acyclic graph of Nodes. Map keys are Node IDs.
@@ -45,10 +165,17 @@ type Graph map[string]*Node
 
 // New constructs a Graph from the given Nodes.
 // Only head Nodes need to be passed to New; these Nodes will be traversed and connected to form the full Graph.
-// Each Node must have a unique ID.
+// Each Node must have a unique ID, or ErrDuplicateID is returned. An empty ID causes ErrEmptyID to be returned.
 // If the Graph contains a cycle, ErrCycle is returned.
+// If a Node is connected to the same Next Node more than once, ErrDuplicateEdge is returned.
 // If one or more Nodes have no path to the rest of the Nodes, ErrDisconnected is returned.
 func New(nodes ...*Node) (Graph, error) {
+	return NewWithOptions(nodes)
+}
+
+// NewWithOptions constructs a Graph from the given Nodes like New, with validation adjusted by the given Options.
+func NewWithOptions(nodes []*Node, opts ...Option) (Graph, error) {
+	cfg := newConfig(opts)
 	g := Graph(make(map[string]*Node, len(nodes)))
 
 	// Add every Node to the Graph while checking for cycles.
@@ -61,10 +188,24 @@ func New(nodes ...*Node) (Graph, error) {
 					return ErrCycle
 				}
 			}
-			if _, ok := g[current.ID]; ok {
+			if current.ID == "" {
+				return ErrEmptyID
+			}
+			if existing, ok := g[current.ID]; ok {
+				if existing != current {
+					return fmt.Errorf("%w: %s", ErrDuplicateID, current.ID)
+				}
 				// Node was already recorded, ok to skip.
 				return nil
 			}
+			if cfg.idValidator != nil {
+				if err := cfg.idValidator(current.ID); err != nil {
+					return fmt.Errorf("invalid node id %q: %w", current.ID, err)
+				}
+			}
+			if cfg.maxNodes > 0 && len(g) >= cfg.maxNodes {
+				return fmt.Errorf("%w: more than %d", ErrTooManyNodes, cfg.maxNodes)
+			}
 			g[current.ID] = current
 			return nil
 		}, []*Node{})
@@ -74,89 +215,174 @@ func New(nodes ...*Node) (Graph, error) {
 		}
 	}
 
+	// Check for Nodes that are connected to the same Node more than once.
+	for _, n := range g {
+		seen := make(map[*Node]bool, len(n.Next))
+		for _, next := range n.Next {
+			if seen[next] {
+				return nil, fmt.Errorf("%w: %s to %s", ErrDuplicateEdge, n.ID, next.ID)
+			}
+			seen[next] = true
+		}
+	}
+
+	// Check that no Node has more parents than it was connected to, which would break the accounting of its inputs.
+	for n, parents := range g.parents() {
+		if len(parents) > n.indegree {
+			return nil, fmt.Errorf("%w: node %s has %d parents but was connected to %d",
+				ErrInconsistentNode, n.ID, len(parents), n.indegree)
+		}
+	}
+
+	// Connect the synthetic source to every root.
+	if cfg.source != nil {
+		if err := g.addSynthetic(cfg.source.id, cfg.source.eval, func(source *Node) {
+			for _, root := range g.Roots() {
+				source.connect(root)
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Connect every leaf to the synthetic sink.
+	if cfg.sink != nil {
+		if err := g.addSynthetic(cfg.sink.id, cfg.sink.eval, func(sink *Node) {
+			for _, leaf := range g.Leaves() {
+				leaf.connect(sink)
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+
 	// Check connectivity.
-	if err := g.CheckConnectivity(); err != nil {
+	if cfg.components != nil {
+		if err := g.checkComponents(cfg.components); err != nil {
+			return nil, err
+		}
+	} else if err := g.CheckConnectivity(); err != nil {
 		return nil, err
 	}
 
 	return g, nil
 }
 
+// addSynthetic adds a new Node with the given ID and EvalFunc to the Graph after connecting it with connect.
+// If the ID is empty or already used, ErrEmptyID or ErrDuplicateID is returned.
+func (g Graph) addSynthetic(id string, eval EvalFunc, connect func(*Node)) error {
+	if id == "" {
+		return ErrEmptyID
+	}
+	if _, ok := g[id]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateID, id)
+	}
+	c := g.structure()
+	n := newNode(id, eval)
+	connect(n)
+	g[id] = n
+	c.add(n)
+	return nil
+}
+
 // ErrCycle is returned when a cycle is detected in a Graph.
 var ErrCycle = errors.New("cycle detected")
 
 // ErrDisconnected is returned when a Node is unreachable from at least one Node in the same Graph.
 var ErrDisconnected = errors.New("disconnected node")
 
+// ErrDuplicateEdge is returned when a Node is connected to the same Next Node more than once.
+var ErrDuplicateEdge = errors.New("duplicate edge")
+
+// ErrInconsistentNode is returned when a Node has more parents than it was connected to,
+// for example after a Next list was appended to directly instead of using NewNode.
+var ErrInconsistentNode = errors.New("inconsistent node")
+
+// ErrUnknownNode is returned when a Node ID is not present in a Graph.
+var ErrUnknownNode = errors.New("unknown node")
+
+// ErrTooManyNodes is returned when a Graph has more Nodes than allowed by WithMaxNodes.
+var ErrTooManyNodes = errors.New("too many nodes")
+
 // CheckConnectivity returns ErrDisconnect if the Graph is disconnected.
 func (g Graph) CheckConnectivity() error {
-	var connected = map[string]map[string]bool{}
-
-	// Initialize a connectivity map that records whether a Node connects to each other Node.
-	// The structure of the map is [Node A ID] -> [Node B ID] -> Is Connected (bool).
-	for _, src := range g {
-		connected[src.ID] = make(map[string]bool)
-	inner:
-		for _, dst := range g {
-			if dst.ID == src.ID {
-				log.Printf("skipping %s to %s", src.ID, dst.ID)
-				continue inner
-			}
-			log.Printf("init connection: %s to %s", src.ID, dst.ID)
-			connected[src.ID][dst.ID] = false
+	component := g.components()
+
+	// If any Node is in a different component from another Node, return ErrDisconnected.
+	var first *Node
+	for _, n := range g {
+		if first == nil {
+			first = n
+		} else if component[n] != component[first] {
+			log.Printf("disconnect: node %s is not connected to node %s", first.ID, n.ID)
+			return ErrDisconnected
 		}
 	}
 
-	// Traverse the Graph depth-first to check for cycles while recording connectivity.
-	g.Walk(func(current *Node, prev []*Node) error {
-		for _, p := range prev {
-			// Mark each previously visited Node as connected to this Node and its connections, and vice versa.
-			log.Printf("connected: %s to %s", current.ID, p.ID)
-			connected[current.ID][p.ID] = true
-			connected[p.ID][current.ID] = true
-			for connID, ok := range connected[current.ID] {
-				if ok {
-					connected[p.ID][connID] = true
-				}
-			}
-			for connID, ok := range connected[p.ID] {
-				if ok {
-					connected[current.ID][connID] = true
-				}
-			}
+	return nil
+}
+
+// components labels each Node with the index of its weakly connected component: two Nodes have
+// the same index if and only if there is a path between them when edge directions are ignored.
+func (g Graph) components() map[*Node]int {
+	// Record the edges in both directions.
+	neighbors := make(map[*Node][]*Node, len(g))
+	for _, n := range g {
+		for _, next := range n.Next {
+			neighbors[n] = append(neighbors[n], next)
+			neighbors[next] = append(neighbors[next], n)
 		}
-		return nil
-	})
+	}
 
-	reversed := g.Reversed()
-
-	// For every Node in the reversed graph, complete the connectivity check by doing
-	// another depth-first traversal and marking all Nodes reached.
-	reversed.Walk(func(current *Node, prev []*Node) error {
-		for _, p := range prev {
-			connected[current.ID][p.ID] = true
-			connected[p.ID][current.ID] = true
-			for connID, ok := range connected[current.ID] {
-				if ok {
-					connected[p.ID][connID] = true
-				}
-			}
-			for connID, ok := range connected[p.ID] {
-				if ok {
-					connected[current.ID][connID] = true
+	// Traverse from each unlabeled Node, labeling every Node reached with a new component index.
+	component := make(map[*Node]int, len(g))
+	count := 0
+	for _, start := range g {
+		if _, ok := component[start]; ok {
+			continue
+		}
+		component[start] = count
+		stack := []*Node{start}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, neighbor := range neighbors[n] {
+				if _, ok := component[neighbor]; !ok {
+					component[neighbor] = count
+					stack = append(stack, neighbor)
 				}
 			}
 		}
-		return nil
-	})
+		count++
+	}
+	return component
+}
+
+// checkComponents returns ErrDisconnected unless the Nodes of each group are connected to each other,
+// and every Node is connected to at least one group. Groups are not required to be connected to each other.
+// If a group contains an ID that is not in the Graph, ErrUnknownNode is returned.
+func (g Graph) checkComponents(groups [][]string) error {
+	component := g.components()
 
-	// If any Nodes have not reached any other Nodes, return ErrDisconnected.
-	for src, dst := range connected {
-		for dst, reached := range dst {
-			if !reached {
-				log.Printf("disconnect: node %s is not connected to node %s", src, dst)
+	declared := map[int]bool{}
+	for _, group := range groups {
+		for _, id := range group {
+			n, ok := g[id]
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrUnknownNode, id)
+			}
+			if first := g[group[0]]; component[n] != component[first] {
+				log.Printf("disconnect: node %s is not connected to node %s", first.ID, n.ID)
 				return ErrDisconnected
 			}
+			declared[component[n]] = true
+		}
+	}
+
+	for _, n := range g {
+		if !declared[component[n]] {
+			log.Printf("disconnect: node %s is not connected to any declared component", n.ID)
+			return ErrDisconnected
 		}
 	}
 
@@ -174,25 +400,142 @@ func (g Graph) Filter(filter func(*Node) bool) []*Node {
 	return out
 }
 
+// SortedNodes returns every Node in the Graph sorted by ID, for iteration in a deterministic order.
+func (g Graph) SortedNodes() []*Node {
+	nodes := g.Filter(func(*Node) bool { return true })
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+// Contains reports whether the Graph contains a Node with the given ID.
+func (g Graph) Contains(id string) bool {
+	_, ok := g[id]
+	return ok
+}
+
+// GetNodes returns the Nodes with the given IDs, in the same order.
+// If the Graph does not contain one of the Nodes, ErrUnknownNode is returned for the first missing ID.
+func (g Graph) GetNodes(ids ...string) ([]*Node, error) {
+	nodes := make([]*Node, len(ids))
+	for i, id := range ids {
+		n, ok := g[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNode, id)
+		}
+		nodes[i] = n
+	}
+	return nodes, nil
+}
+
 // Roots returns the root Nodes of the Graph (Nodes with indegree of 0).
+// The roots are cached until the structure of the Graph changes, so repeated calls only copy them.
 func (g Graph) Roots() []*Node {
-	return g.Filter(func(n *Node) bool { return n.indegree == 0 })
+	c := g.structure()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.roots == nil {
+		c.roots = g.Filter(func(n *Node) bool { return n.indegree == 0 })
+	}
+	return append([]*Node(nil), c.roots...)
+}
+
+// Leaves returns the leaf Nodes of the Graph (Nodes without any Next Nodes).
+// The leaves are cached until the structure of the Graph changes, so repeated calls only copy them.
+func (g Graph) Leaves() []*Node {
+	c := g.structure()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.leaves == nil {
+		c.leaves = g.Filter(func(n *Node) bool { return len(n.Next) == 0 })
+	}
+	return append([]*Node(nil), c.leaves...)
+}
+
+// ReachableFromRoots separates the Nodes of the Graph that can be reached by following edges from a root
+// from the orphans that can't. An orphan still waits for inputs from parents that no longer send to it,
+// for example after an entry was deleted from the Graph's map or removed from a Node's Next slice directly,
+// so it can never be evaluated. Both slices are sorted by ID.
+func (g Graph) ReachableFromRoots() (reachable []*Node, orphans []*Node) {
+	seen := make(map[*Node]bool, len(g))
+	stack := g.Roots()
+	for _, n := range stack {
+		seen[n] = true
+	}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, next := range n.Next {
+			if !seen[next] && g[next.ID] == next {
+				seen[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	reachable, orphans = []*Node{}, []*Node{}
+	for _, n := range g.SortedNodes() {
+		if seen[n] {
+			reachable = append(reachable, n)
+		} else {
+			orphans = append(orphans, n)
+		}
+	}
+	return reachable, orphans
+}
+
+// IsForest reports whether every Node in the Graph has at most one parent.
+func (g Graph) IsForest() bool {
+	return len(g.Filter(func(n *Node) bool { return n.indegree > 1 })) == 0
 }
 
+// IsTree reports whether the Graph is a forest with exactly one root.
+func (g Graph) IsTree() bool {
+	return g.IsForest() && len(g.Roots()) == 1
+}
+
+// ErrStopWalk can be returned by a visit function to stop a walk early.
+// The walk then returns nil instead of the error.
+var ErrStopWalk = errors.New("stop walk")
+
+// ErrSkipChildren can be returned by a visit function to skip the children of the current Node
+// without stopping the rest of the walk. Children reachable through other Nodes are still visited from there.
+var ErrSkipChildren = errors.New("skip children")
+
 // Walk recursively traverses the Graph depth-first, applying the visit function to each visited Node.
 // The visit function also receives the chain of Nodes visited prior to the current Node,
 // sorted so that the root is at index 0 of the slice, and the previously visited Node is at the end of the slice.
+// If the visit function returns an error, the walk stops and the error is returned, unless it is ErrStopWalk.
 func (g Graph) Walk(visit func(current *Node, prev []*Node) error) error {
 	for _, n := range g.Roots() {
 		if err := n.walkRecursive(visit, []*Node{}); err != nil {
-			return err
+			return stopWalk(err)
 		}
 	}
 	return nil
 }
 
+// WalkFrom recursively traverses the Graph depth-first like Walk, but starts from the Node with the given ID
+// instead of from every root. If the Graph does not contain the Node, ErrUnknownNode is returned.
+func (g Graph) WalkFrom(id string, visit func(current *Node, prev []*Node) error) error {
+	n, ok := g[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownNode, id)
+	}
+	return stopWalk(n.walkRecursive(visit, []*Node{}))
+}
+
+// stopWalk returns nil if the error is ErrStopWalk, or the error otherwise.
+func stopWalk(err error) error {
+	if errors.Is(err, ErrStopWalk) {
+		return nil
+	}
+	return err
+}
+
 func (n *Node) walkRecursive(visit func(current *Node, prev []*Node) error, prev []*Node) error {
-	if err := visit(n, prev); err != nil {
+	n.countVisit()
+	if err := visit(n, prev); errors.Is(err, ErrSkipChildren) {
+		return nil
+	} else if err != nil {
 		return err
 	}
 	for _, next := range n.Next {
@@ -203,19 +546,149 @@ func (n *Node) walkRecursive(visit func(current *Node, prev []*Node) error, prev
 	return nil
 }
 
+// WalkOnce recursively traverses the Graph depth-first like Walk, but applies the visit function to each Node
+// only once, with the chain of Nodes on the first path that reached it. Walk visits a Node once per path from
+// a root, which grows quickly in Graphs with many shared descendants.
+func (g Graph) WalkOnce(visit func(current *Node, prev []*Node) error) error {
+	visited := make(map[*Node]bool, len(g))
+	for _, n := range g.Roots() {
+		if err := n.walkOnceRecursive(visit, []*Node{}, visited); err != nil {
+			return stopWalk(err)
+		}
+	}
+	return nil
+}
+
+// WalkTolerant traverses the Nodes reachable from the given Nodes depth-first like WalkOnce, applying the visit
+// function to each Node only once, and never following an edge back to a Node that was already visited.
+// Unlike the methods of Graph, it does not require the Nodes to form a valid Graph: it terminates even if they
+// contain a cycle, for example to inspect or draw Nodes that New rejected with ErrCycle.
+func WalkTolerant(nodes []*Node, visit func(current *Node, prev []*Node) error) error {
+	visited := make(map[*Node]bool, len(nodes))
+	for _, n := range nodes {
+		if err := n.walkOnceRecursive(visit, []*Node{}, visited); err != nil {
+			return stopWalk(err)
+		}
+	}
+	return nil
+}
+
+func (n *Node) walkOnceRecursive(visit func(current *Node, prev []*Node) error, prev []*Node, visited map[*Node]bool) error {
+	if visited[n] {
+		return nil
+	}
+	visited[n] = true
+	n.countVisit()
+	if err := visit(n, prev); errors.Is(err, ErrSkipChildren) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, next := range n.Next {
+		if err := next.walkOnceRecursive(visit, append(prev, n), visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkPostOrder recursively traverses the Graph depth-first like Walk, but applies the visit function
+// to each Node only after it has been applied to all of the Node's descendants.
+// Since the children have already been visited, returning ErrSkipChildren has no effect.
+func (g Graph) WalkPostOrder(visit func(current *Node, prev []*Node) error) error {
+	for _, n := range g.Roots() {
+		if err := n.walkPostOrderRecursive(visit, []*Node{}); err != nil {
+			return stopWalk(err)
+		}
+	}
+	return nil
+}
+
+func (n *Node) walkPostOrderRecursive(visit func(current *Node, prev []*Node) error, prev []*Node) error {
+	for _, next := range n.Next {
+		if err := next.walkPostOrderRecursive(visit, append(prev, n)); err != nil {
+			return err
+		}
+	}
+	n.countVisit()
+	if err := visit(n, prev); !errors.Is(err, ErrSkipChildren) {
+		return err
+	}
+	return nil
+}
+
+// CountVisits enables or disables counting how many times the walks of the Graph, such as Walk and WalkOnce,
+// apply their visit function to each Node, and resets the counts to zero. The counts are read with
+// Node.VisitCount, and show how often a path-based walk such as Walk revisits the shared descendants of
+// diamonds. Walks performed by other methods of the Graph are counted too.
+func (g Graph) CountVisits(enabled bool) {
+	for _, n := range g {
+		n.countVisits.Store(enabled)
+		n.visits.Store(0)
+	}
+}
+
+// VisitCount returns the number of times a walk applied its visit function to the Node since counting was
+// enabled with CountVisits, or zero if counting is disabled.
+func (n *Node) VisitCount() int {
+	return int(n.visits.Load())
+}
+
+func (n *Node) countVisit() {
+	if n.countVisits.Load() {
+		n.visits.Add(1)
+	}
+}
+
+// WalkWithAncestors traverses the Graph in topological order, applying the visit function to each Node once
+// with the set of all of its ancestors by ID, regardless of the paths that lead to it.
+// Each call receives its own map, which the visit function may keep.
+// Returning ErrSkipChildren skips the children of the current Node unless another visited parent reaches them.
+// If the Graph has a cycle, the error from TopologicalSort is returned.
+func (g Graph) WalkWithAncestors(visit func(current *Node, ancestors map[string]*Node) error) error {
+	sorted, err := g.TopologicalSort()
+	if err != nil {
+		return err
+	}
+	parents := g.parents()
+	ancestors := make(map[*Node]map[string]*Node, len(sorted))
+	expanded := make(map[*Node]bool, len(sorted))
+	for _, n := range sorted {
+		set := make(map[string]*Node)
+		reached := len(parents[n]) == 0
+		for _, parent := range parents[n] {
+			set[parent.ID] = parent
+			for id, ancestor := range ancestors[parent] {
+				set[id] = ancestor
+			}
+			reached = reached || expanded[parent]
+		}
+		ancestors[n] = set
+		if !reached {
+			continue
+		}
+		copied := make(map[string]*Node, len(set))
+		for id, ancestor := range set {
+			copied[id] = ancestor
+		}
+		n.countVisit()
+		if err := visit(n, copied); errors.Is(err, ErrSkipChildren) {
+			continue
+		} else if err != nil {
+			return stopWalk(err)
+		}
+		expanded[n] = true
+	}
+	return nil
+}
+
 // Reversed returns a new Graph with the edge directions reversed.
 func (g Graph) Reversed() Graph {
 	result := make(Graph)
 	g.Walk(func(current *Node, prev []*Node) error {
 		// Add a copy of the Node to the reversed Graph without any edges if we haven't done so yet.
 		if _, ok := result[current.ID]; !ok {
-			result[current.ID] = &Node{
-				ID:     current.ID,
-				Next:   []*Node{},
-				eval:   current.eval,
-				wait:   &sync.WaitGroup{},
-				inputs: make(chan int),
-			}
+			result[current.ID] = current.copyWithoutEdges()
 		}
 		// If the current Node has no parent, continue.
 		if len(prev) == 0 {
@@ -229,8 +702,20 @@ func (g Graph) Reversed() Graph {
 				return nil
 			}
 		}
-		result[current.ID].Next = append(result[current.ID].Next, result[parent.ID])
+		result[current.ID].connect(result[parent.ID])
 		return nil
 	})
 	return result
 }
+
+// copyMetadata returns a shallow copy of the given metadata map, or nil if it is nil.
+func copyMetadata(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -3,7 +3,10 @@ package dag
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 var graphTestCases = []struct {
@@ -41,3 +44,640 @@ func TestGraph(t *testing.T) {
 		})
 	}
 }
+
+func TestMetadata(t *testing.T) {
+	sum := NewNode("sum", Sum)
+	sum.Metadata = map[string]string{"owner": "alice"}
+	graph, err := New(NewNode("1", Constant(1), sum), NewNode("2", Constant(2), sum))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Metadata is copied into the reversed Graph rather than shared with it.
+	reversed := graph.Reversed()
+	if owner := reversed["sum"].Metadata["owner"]; owner != "alice" {
+		t.Fatalf("unexpected metadata in reversed graph: want alice but got %q", owner)
+	}
+	reversed["sum"].Metadata["owner"] = "bob"
+	if owner := graph["sum"].Metadata["owner"]; owner != "alice" {
+		t.Fatalf("reversed graph shares metadata with the original: got %q", owner)
+	}
+
+	// Metadata is copied into a Clone.
+	clone := graph.Clone()
+	if owner := clone["sum"].Metadata["owner"]; owner != "alice" {
+		t.Fatalf("unexpected metadata in clone: want alice but got %q", owner)
+	}
+	clone["sum"].Metadata["owner"] = "bob"
+	if owner := graph["sum"].Metadata["owner"]; owner != "alice" {
+		t.Fatalf("clone shares metadata with the original: got %q", owner)
+	}
+
+	// Metadata is copied into an induced subgraph; "sum" is reachable from both roots, so it is shared.
+	shared := graph.PartitionByRoot()[SharedPartition]
+	if owner := shared["sum"].Metadata["owner"]; owner != "alice" {
+		t.Fatalf("unexpected metadata in subgraph: want alice but got %q", owner)
+	}
+	shared["sum"].Metadata["owner"] = "bob"
+	if owner := graph["sum"].Metadata["owner"]; owner != "alice" {
+		t.Fatalf("subgraph shares metadata with the original: got %q", owner)
+	}
+
+	// Metadata does not affect evaluation.
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["sum"].Result; result != 3 {
+		t.Fatalf("unexpected result for node sum: want 3 but got %d", result)
+	}
+}
+
+func TestWalkFrom(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		From   string
+		Expect []string
+	}{
+		{From: "min", Expect: []string{"min", "sum"}},
+		{From: "sum", Expect: []string{"sum"}},
+		{From: "1", Expect: []string{"1", "max", "sum"}},
+	} {
+		visited := []string{}
+		err := graph.WalkFrom(test.From, func(current *Node, prev []*Node) error {
+			visited = append(visited, current.ID)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error walking from %s: %s", test.From, err)
+		}
+		if fmt.Sprint(visited) != fmt.Sprint(test.Expect) {
+			t.Fatalf("unexpected walk from %s: want %v but got %v", test.From, test.Expect, visited)
+		}
+	}
+	if err := graph.WalkFrom("nope", func(*Node, []*Node) error { return nil }); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode walking from an unknown node but got %v", err)
+	}
+}
+
+func TestWalkPostOrder(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	visited := map[string]bool{}
+	err = graph.WalkPostOrder(func(current *Node, prev []*Node) error {
+		// Every descendant must have been visited before the current Node.
+		err := graph.WalkFrom(current.ID, func(desc *Node, _ []*Node) error {
+			if desc != current && !visited[desc.ID] {
+				t.Fatalf("node %s visited before its descendant %s", current.ID, desc.ID)
+			}
+			return nil
+		})
+		visited[current.ID] = true
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(visited) != len(graph) {
+		t.Fatalf("expected %d nodes to be visited but got %d", len(graph), len(visited))
+	}
+}
+
+func TestIsTree(t *testing.T) {
+	for i, test := range []struct {
+		Name         string
+		Graph        func() (Graph, error)
+		ExpectTree   bool
+		ExpectForest bool
+	}{
+		{
+			Name:         "assignment",
+			Graph:        assignmentGraph,
+			ExpectTree:   false,
+			ExpectForest: false,
+		},
+		{
+			Name: "chain",
+			Graph: func() (Graph, error) {
+				return New(NewNode("1", Constant(1), NewNode("2", Sum, NewNode("3", Sum))))
+			},
+			ExpectTree:   true,
+			ExpectForest: true,
+		},
+		{
+			Name: "split",
+			Graph: func() (Graph, error) {
+				return New(NewNode("1", Constant(1), NewNode("min", Min), NewNode("max", Max)))
+			},
+			ExpectTree:   true,
+			ExpectForest: true,
+		},
+		{
+			Name: "diamond",
+			Graph: func() (Graph, error) {
+				sum := NewNode("sum", Sum)
+				return New(NewNode("1", Constant(1), NewNode("min", Min, sum), NewNode("max", Max, sum)))
+			},
+			ExpectTree:   false,
+			ExpectForest: false,
+		},
+	} {
+		t.Run(fmt.Sprintf("%d_%s", i, test.Name), func(t *testing.T) {
+			graph, err := test.Graph()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if isTree := graph.IsTree(); isTree != test.ExpectTree {
+				t.Fatalf("unexpected IsTree result: want %t but got %t", test.ExpectTree, isTree)
+			}
+			if isForest := graph.IsForest(); isForest != test.ExpectForest {
+				t.Fatalf("unexpected IsForest result: want %t but got %t", test.ExpectForest, isForest)
+			}
+		})
+	}
+}
+
+func TestEvaluateReversed(t *testing.T) {
+	graph, err := New(NewNode("b", Max, NewNode("a", Constant(7))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reversed := graph.Reversed()
+	done := make(chan error)
+	go func() {
+		done <- reversed.Evaluate(2)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("evaluation of the reversed graph did not complete")
+	}
+	if result := reversed["b"].Result; result != 7 {
+		t.Fatalf("unexpected result for node b: want 7 but got %d", result)
+	}
+}
+
+func TestWithComponents(t *testing.T) {
+	pipelines := func() []*Node {
+		return []*Node{
+			NewNode("a1", Constant(1), NewNode("a2", Sum)),
+			NewNode("b1", Constant(2), NewNode("b2", Sum)),
+		}
+	}
+	components := WithComponents([][]string{{"a1", "a2"}, {"b1"}})
+
+	if _, err := New(pipelines()...); !errors.Is(err, ErrDisconnected) {
+		t.Fatalf("expected ErrDisconnected without declared components but got %v", err)
+	}
+	graph, err := NewWithOptions(pipelines(), components)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["b2"].Result; result != 2 {
+		t.Fatalf("unexpected result for node b2: want 2 but got %d", result)
+	}
+
+	// A Node outside of every declared component is still rejected.
+	stray := append(pipelines(), NewNode("stray", Constant(3)))
+	if _, err := NewWithOptions(stray, components); !errors.Is(err, ErrDisconnected) {
+		t.Fatalf("expected ErrDisconnected for a stray node but got %v", err)
+	}
+
+	// A declared component must itself be connected.
+	split := WithComponents([][]string{{"a1", "b1"}})
+	if _, err := NewWithOptions(pipelines(), split); !errors.Is(err, ErrDisconnected) {
+		t.Fatalf("expected ErrDisconnected for a disconnected component but got %v", err)
+	}
+
+	unknown := WithComponents([][]string{{"a1", "nope"}, {"b1"}})
+	if _, err := NewWithOptions(pipelines(), unknown); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+}
+
+func TestConnectivity(t *testing.T) {
+	// Both Nodes are connected through "sum" and "fast" even though neither is an ancestor of the other.
+	for i := 0; i < 20; i++ {
+		sum := NewNode("sum", Sum)
+		_, err := New(NewNode("fast", Constant(1), sum, NewNode("double", Sum)), NewNode("slow", Constant(2), sum))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDuplicateEdge(t *testing.T) {
+	// NewNode connects a repeated Node only once, so "sum" is not double counted.
+	sum := NewNode("sum", Sum)
+	max := NewNode("max", Max, sum)
+	graph, err := New(NewNode("1", Constant(1), max, max), NewNode("2", Constant(2), sum))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(graph["1"].Next) != 1 {
+		t.Fatalf("expected the duplicate edge to be skipped but got %d edges", len(graph["1"].Next))
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["sum"].Result; result != 3 {
+		t.Fatalf("unexpected result for node sum: want 3 but got %d", result)
+	}
+
+	if err := graph.Clone().AddEdge("1", "max"); !errors.Is(err, ErrDuplicateEdge) {
+		t.Fatalf("expected ErrDuplicateEdge from AddEdge but got %v", err)
+	}
+	_, err = NewGraphBuilder().Node("1", Constant(1)).Node("max", Max).Edge("1", "max").Edge("1", "max").Build()
+	if !errors.Is(err, ErrDuplicateEdge) {
+		t.Fatalf("expected ErrDuplicateEdge from Build but got %v", err)
+	}
+
+	// Appending to Next directly bypasses NewNode, so New rejects the duplicate.
+	a, b := NewNode("a", Constant(1)), NewNode("b", Sum)
+	a.Next = append(a.Next, b, b)
+	if _, err := New(a); !errors.Is(err, ErrDuplicateEdge) {
+		t.Fatalf("expected ErrDuplicateEdge from New but got %v", err)
+	}
+}
+
+func TestInconsistentNode(t *testing.T) {
+	// Appending to Next directly leaves the child expecting fewer inputs than it has parents.
+	child := NewNode("child", Sum)
+	parent := NewNode("parent", Constant(1))
+	parent.Next = append(parent.Next, child)
+	if _, err := New(NewNode("root", Constant(1), child), parent); !errors.Is(err, ErrInconsistentNode) {
+		t.Fatalf("expected ErrInconsistentNode for the extra parent but got %v", err)
+	}
+}
+
+func TestStopWalk(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	visited := []string{}
+	err = graph.Walk(func(current *Node, prev []*Node) error {
+		visited = append(visited, current.ID)
+		return ErrStopWalk
+	})
+	if err != nil {
+		t.Fatalf("expected ErrStopWalk to be swallowed but got %v", err)
+	}
+	if len(visited) != 1 {
+		t.Fatalf("expected 1 node to be visited but got %v", visited)
+	}
+
+	// Wrapped sentinels also stop the walk.
+	visited = []string{}
+	err = graph.WalkFrom("1", func(current *Node, prev []*Node) error {
+		visited = append(visited, current.ID)
+		if current.ID == "max" {
+			return fmt.Errorf("found it: %w", ErrStopWalk)
+		}
+		return nil
+	})
+	if err != nil || fmt.Sprint(visited) != "[1 max]" {
+		t.Fatalf("expected the walk to stop at max without error but got %v and %v", visited, err)
+	}
+}
+
+func TestSkipChildren(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parents := map[string][]string{}
+	err = graph.Walk(func(current *Node, prev []*Node) error {
+		if len(prev) > 0 {
+			parents[current.ID] = append(parents[current.ID], prev[len(prev)-1].ID)
+		}
+		if current.ID == "max" {
+			return ErrSkipChildren
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, parent := range parents["sum"] {
+		if parent != "min" {
+			t.Fatalf("expected sum to be visited only via min but got %v", parents["sum"])
+		}
+	}
+	if len(parents["sum"]) != 2 {
+		t.Fatalf("expected sum to be visited twice via min but got %v", parents["sum"])
+	}
+	if len(parents["max"]) != 2 {
+		t.Fatalf("expected max to still be visited from both parents but got %v", parents["max"])
+	}
+}
+
+func TestWithMaxNodes(t *testing.T) {
+	sum := NewNode("sum", Sum)
+	nodes := []*Node{}
+	for i := 0; i < 10; i++ {
+		nodes = append(nodes, NewNode(fmt.Sprint(i), Constant(i), sum))
+	}
+	if _, err := NewWithOptions(nodes, WithMaxNodes(10)); !errors.Is(err, ErrTooManyNodes) {
+		t.Fatalf("expected ErrTooManyNodes but got %v", err)
+	}
+	graph, err := NewWithOptions(nodes, WithMaxNodes(11))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(graph) != 11 {
+		t.Fatalf("expected 11 nodes but got %d", len(graph))
+	}
+}
+
+func TestContains(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !graph.Contains("sum") {
+		t.Fatal("expected the graph to contain node sum")
+	}
+	if graph.Contains("nope") {
+		t.Fatal("expected the graph not to contain node nope")
+	}
+}
+
+func TestReachableFromRoots(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reachable, orphans := graph.ReachableFromRoots()
+	if len(reachable) != len(graph) || len(orphans) != 0 {
+		t.Fatalf("expected every node to be reachable but got orphans %v", nodeIDs(orphans))
+	}
+
+	// Removing the edges from 1 and 2 directly leaves max waiting for inputs that never arrive.
+	graph["1"].Next = nil
+	graph["2"].Next = nil
+	reachable, orphans = graph.ReachableFromRoots()
+	if ids := fmt.Sprint(nodeIDs(orphans)); ids != "[max]" {
+		t.Fatalf("unexpected orphans: want [max] but got %s", ids)
+	}
+	if ids := fmt.Sprint(nodeIDs(reachable)); ids != "[1 2 3 4 min sum]" {
+		t.Fatalf("unexpected reachable nodes: got %s", ids)
+	}
+}
+
+func TestWalkWithAncestors(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	visits := map[string][]string{}
+	err = graph.WalkWithAncestors(func(current *Node, ancestors map[string]*Node) error {
+		ids := make([]string, 0, len(ancestors))
+		for id := range ancestors {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		visits[current.ID] = ids
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(visits) != len(graph) {
+		t.Fatalf("expected %d nodes to be visited but got %d", len(graph), len(visits))
+	}
+	if ids := fmt.Sprint(visits["sum"]); ids != "[1 2 3 4 max min]" {
+		t.Fatalf("unexpected ancestors of sum: got %s", ids)
+	}
+	if ids := fmt.Sprint(visits["max"]); ids != "[1 2]" {
+		t.Fatalf("unexpected ancestors of max: got %s", ids)
+	}
+	if ids := fmt.Sprint(visits["1"]); ids != "[]" {
+		t.Fatalf("unexpected ancestors of 1: got %s", ids)
+	}
+}
+
+func TestWalkTolerant(t *testing.T) {
+	a, b := NewNode("a", Constant(1)), NewNode("b", Constant(2))
+	a.Next = append(a.Next, b)
+	b.Next = append(b.Next, a)
+	if _, err := New(a, b); !errors.Is(err, ErrCycle) {
+		t.Fatalf("expected ErrCycle but got %v", err)
+	}
+
+	visits := map[string]int{}
+	done := make(chan error)
+	go func() {
+		done <- WalkTolerant([]*Node{a, b}, func(current *Node, prev []*Node) error {
+			visits[current.ID]++
+			return nil
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("walk of a cycle did not terminate")
+	}
+	if fmt.Sprint(visits) != "map[a:1 b:1]" {
+		t.Fatalf("expected each node to be visited once but got %v", visits)
+	}
+}
+
+func TestVisitCount(t *testing.T) {
+	// In a diamond, Walk reaches the shared descendant once through each branch.
+	bottom := NewNode("bottom", Sum)
+	graph, err := New(NewNode("top", Constant(1), NewNode("left", Sum, bottom), NewNode("right", Sum, bottom)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	visit := func(*Node, []*Node) error { return nil }
+	if err := graph.Walk(visit); err != nil {
+		t.Fatal(err)
+	}
+	if count := graph["bottom"].VisitCount(); count != 0 {
+		t.Fatalf("expected no visits to be counted before CountVisits but got %d", count)
+	}
+
+	graph.CountVisits(true)
+	if err := graph.Walk(visit); err != nil {
+		t.Fatal(err)
+	}
+	for id, expect := range map[string]int{"top": 1, "left": 1, "right": 1, "bottom": 2} {
+		if count := graph[id].VisitCount(); count != expect {
+			t.Fatalf("unexpected visit count for node %s: want %d but got %d", id, expect, count)
+		}
+	}
+
+	// Enabling counting again resets the counts, and WalkOnce visits each Node once.
+	graph.CountVisits(true)
+	if err := graph.WalkOnce(visit); err != nil {
+		t.Fatal(err)
+	}
+	if count := graph["bottom"].VisitCount(); count != 1 {
+		t.Fatalf("unexpected visit count for node bottom with WalkOnce: want 1 but got %d", count)
+	}
+}
+
+func TestWalkOnce(t *testing.T) {
+	// A chain of diamonds has 2^n paths to its last Node.
+	bottom := NewNode("bottom", Sum)
+	next := bottom
+	for i := 0; i < 10; i++ {
+		next = NewNode(fmt.Sprint("top", i), Sum,
+			NewNode(fmt.Sprint("left", i), Sum, next),
+			NewNode(fmt.Sprint("right", i), Sum, next))
+	}
+	graph, err := New(next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	visits := map[string]int{}
+	err = graph.WalkOnce(func(current *Node, prev []*Node) error {
+		visits[current.ID]++
+		for i, p := range prev {
+			if i > 0 && !prev[i-1].hasEdgeTo(p) {
+				t.Fatalf("invalid prev chain for node %s", current.ID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(visits) != len(graph) {
+		t.Fatalf("expected %d nodes to be visited but got %d", len(graph), len(visits))
+	}
+	for id, count := range visits {
+		if count != 1 {
+			t.Fatalf("expected node %s to be visited once but got %d", id, count)
+		}
+	}
+}
+
+func TestWithIDValidator(t *testing.T) {
+	errSpace := errors.New("id contains a space")
+	noSpaces := WithIDValidator(func(id string) error {
+		if strings.Contains(id, " ") {
+			return errSpace
+		}
+		return nil
+	})
+	if _, err := NewWithOptions([]*Node{NewNode("good", Constant(1), NewNode("bad id", Sum))}, noSpaces); !errors.Is(err, errSpace) {
+		t.Fatalf("expected the validator's error but got %v", err)
+	}
+	if _, err := NewWithOptions([]*Node{NewNode("good", Constant(1), NewNode("also_good", Sum))}, noSpaces); err != nil {
+		t.Fatal(err)
+	}
+
+	// Distinct Nodes with the same ID are always rejected.
+	if _, err := New(NewNode("1", Constant(1), NewNode("dup", Sum)), NewNode("dup", Constant(2))); !errors.Is(err, ErrDuplicateID) {
+		t.Fatalf("expected ErrDuplicateID but got %v", err)
+	}
+}
+
+func TestEmptyID(t *testing.T) {
+	if _, err := New(NewNode("1", Constant(1), NewNode("", Sum))); !errors.Is(err, ErrEmptyID) {
+		t.Fatalf("expected ErrEmptyID but got %v", err)
+	}
+	if _, err := NewGraphBuilder().Node("", Constant(1)).Build(); !errors.Is(err, ErrEmptyID) {
+		t.Fatalf("expected ErrEmptyID from the builder but got %v", err)
+	}
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.AddNode("", Sum); !errors.Is(err, ErrEmptyID) {
+		t.Fatalf("expected ErrEmptyID from AddNode but got %v", err)
+	}
+	if _, err := graph.Relabel(map[string]string{"sum": ""}); !errors.Is(err, ErrEmptyID) {
+		t.Fatalf("expected ErrEmptyID from Relabel but got %v", err)
+	}
+}
+
+func TestGetNodes(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := graph.GetNodes("1", "max")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := fmt.Sprint(nodeIDs(nodes)); ids != "[1 max]" {
+		t.Fatalf("unexpected nodes: want [1 max] but got %s", ids)
+	}
+	if _, err := graph.GetNodes("1", "nope", "also_nope"); !errors.Is(err, ErrUnknownNode) || !strings.Contains(err.Error(), "nope") || strings.Contains(err.Error(), "also_nope") {
+		t.Fatalf("expected ErrUnknownNode for the first missing node but got %v", err)
+	}
+}
+
+func TestWithSyntheticSink(t *testing.T) {
+	graph, err := NewWithOptions(
+		[]*Node{NewNode("1", Constant(1), NewNode("min", Min), NewNode("max", Max))},
+		WithSyntheticSink("total", Sum),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaves := graph.Leaves(); len(leaves) != 1 || leaves[0].ID != "total" {
+		t.Fatalf("expected total to be the only leaf but got %v", nodeIDs(leaves))
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["total"].Result; result != graph["min"].Result+graph["max"].Result {
+		t.Fatalf("unexpected result for node total: want %d but got %d", graph["min"].Result+graph["max"].Result, result)
+	}
+
+	if _, err := NewWithOptions([]*Node{NewNode("1", Constant(1))}, WithSyntheticSink("1", Sum)); !errors.Is(err, ErrDuplicateID) {
+		t.Fatalf("expected ErrDuplicateID but got %v", err)
+	}
+}
+
+func TestWithSyntheticSource(t *testing.T) {
+	sum := NewNode("sum", Sum)
+	graph, err := NewWithOptions(
+		[]*Node{NewNode("a", Sum, sum), NewNode("b", Max, sum)},
+		WithSyntheticSource("seed", Constant(7)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if roots := graph.Roots(); len(roots) != 1 || roots[0].ID != "seed" {
+		t.Fatalf("expected seed to be the only root but got %v", nodeIDs(roots))
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b"} {
+		if result := graph[id].Result; result != 7 {
+			t.Fatalf("expected node %s to receive the seed but got %d", id, result)
+		}
+	}
+	if result := graph["sum"].Result; result != 14 {
+		t.Fatalf("unexpected result for node sum: want 14 but got %d", result)
+	}
+}
+
+func TestSortedNodes(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := fmt.Sprint(nodeIDs(graph.SortedNodes())); ids != "[1 2 3 4 max min sum]" {
+		t.Fatalf("unexpected order: got %s", ids)
+	}
+}
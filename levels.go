@@ -0,0 +1,92 @@
+package dag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Levels partitions the Nodes of the Graph by level, where the level of a Node is the number of edges
+// on the longest path from any root to the Node. Roots are at level 0.
+// Every Node in a level only depends on Nodes in earlier levels, so each level can be evaluated in parallel.
+// Nodes within a level are sorted by ID.
+func (g Graph) Levels() ([][]*Node, error) {
+	levels := [][]*Node{}
+	err := g.WalkLevels(func(_ int, nodes []*Node) error {
+		levels = append(levels, nodes)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// Height returns the number of levels of the Graph, as partitioned by Levels, which is the number of Nodes
+// on the longest path from a root to a leaf. It returns zero if the Graph is empty or cannot be sorted.
+func (g Graph) Height() int {
+	levels, err := g.Levels()
+	if err != nil {
+		return 0
+	}
+	return len(levels)
+}
+
+// Width returns the largest number of Nodes in any level of the Graph, as partitioned by Levels,
+// which bounds the number of Nodes that can be evaluated in parallel level by level.
+// It returns zero if the Graph is empty or cannot be sorted.
+func (g Graph) Width() int {
+	levels, err := g.Levels()
+	if err != nil {
+		return 0
+	}
+	width := 0
+	for _, level := range levels {
+		if len(level) > width {
+			width = len(level)
+		}
+	}
+	return width
+}
+
+// WalkLevels applies the visit function to the Nodes of each level in ascending order, as partitioned by Levels.
+// If the visit function returns an error, the walk stops and the error is returned, unless it is ErrStopWalk.
+func (g Graph) WalkLevels(visit func(level int, nodes []*Node) error) error {
+	sorted, err := g.TopologicalSort()
+	if err != nil {
+		return fmt.Errorf("topological sort: %w", err)
+	}
+
+	// Visit Nodes in topological order so that each Node's level is final before it is passed on.
+	levelOf := make(map[*Node]int, len(sorted))
+	byLevel := [][]*Node{}
+	for _, node := range sorted {
+		level := levelOf[node]
+		if level == len(byLevel) {
+			byLevel = append(byLevel, []*Node{})
+		}
+		byLevel[level] = append(byLevel[level], node)
+		for _, next := range node.Next {
+			if level+1 > levelOf[next] {
+				levelOf[next] = level + 1
+			}
+		}
+	}
+
+	for level, nodes := range byLevel {
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+		if err := visit(level, nodes); err != nil {
+			return stopWalk(err)
+		}
+	}
+	return nil
+}
+
+// formatLevels formats the IDs of the Nodes in each level for logging, such as "0:[a b] 1:[c]".
+func formatLevels(levels [][]*Node) string {
+	parts := make([]string, len(levels))
+	for i, level := range levels {
+		parts[i] = fmt.Sprintf("%d:%v", i, nodeIDs(level))
+	}
+	return strings.Join(parts, " ")
+}
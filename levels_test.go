@@ -0,0 +1,81 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	levels, err := graph.Levels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := [][]string{{"1", "2", "3", "4"}, {"max", "min"}, {"sum"}}
+	if len(levels) != len(expect) {
+		t.Fatalf("expected %d levels but got %d", len(expect), len(levels))
+	}
+	for i, nodes := range levels {
+		if ids := nodeIDs(nodes); fmt.Sprint(ids) != fmt.Sprint(expect[i]) {
+			t.Fatalf("unexpected nodes at level %d: want %v but got %v", i, expect[i], ids)
+		}
+	}
+}
+
+func TestHeightAndWidth(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height := graph.Height(); height != 3 {
+		t.Fatalf("unexpected height: want 3 but got %d", height)
+	}
+	if width := graph.Width(); width != 4 {
+		t.Fatalf("unexpected width: want 4 but got %d", width)
+	}
+	if height, width := (Graph{}).Height(), (Graph{}).Width(); height != 0 || width != 0 {
+		t.Fatalf("expected an empty graph to have no height or width but got %d and %d", height, width)
+	}
+}
+
+func TestWalkLevels(t *testing.T) {
+	// A Node's level is its longest distance from a root, so "sum" is placed after "double".
+	sum := NewNode("sum", Sum)
+	graph, err := New(NewNode("1", Constant(1), sum, NewNode("double", Sum, sum)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := [][]string{{"1"}, {"double"}, {"sum"}}
+	visited := 0
+	err = graph.WalkLevels(func(level int, nodes []*Node) error {
+		if level != visited {
+			t.Fatalf("unexpected level: want %d but got %d", visited, level)
+		}
+		if ids := nodeIDs(nodes); fmt.Sprint(ids) != fmt.Sprint(expect[level]) {
+			t.Fatalf("unexpected nodes at level %d: want %v but got %v", level, expect[level], ids)
+		}
+		visited++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if visited != len(expect) {
+		t.Fatalf("expected %d levels to be visited but got %d", len(expect), visited)
+	}
+
+	// Returning an error stops the walk.
+	stop := errors.New("stop")
+	visited = 0
+	err = graph.WalkLevels(func(level int, nodes []*Node) error {
+		visited++
+		return stop
+	})
+	if !errors.Is(err, stop) || visited != 1 {
+		t.Fatalf("expected the walk to stop after 1 level with the visit error but got %d levels and %v", visited, err)
+	}
+}
@@ -0,0 +1,31 @@
+package dag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToMermaid returns a Mermaid flowchart of the Graph, drawn from top to bottom, for embedding in Markdown.
+// Nodes are declared in order of ID and refer to each other by their position in that order,
+// so any ID can be used; each Node is labeled with its ID and, if it has been evaluated, its Result.
+// Edges are listed in the same order, following the order of each Node's Next Nodes.
+func (g Graph) ToMermaid() string {
+	nodes := g.SortedNodes()
+	names := make(map[*Node]string, len(nodes))
+	b := &strings.Builder{}
+	b.WriteString("graph TD\n")
+	for i, n := range nodes {
+		names[n] = fmt.Sprintf("n%d", i)
+		label := n.ID
+		if n.evaluated.Load() {
+			label = fmt.Sprintf("%s = %d", n.ID, n.Result)
+		}
+		fmt.Fprintf(b, "    %s[\"%s\"]\n", names[n], strings.ReplaceAll(label, `"`, "#quot;"))
+	}
+	for _, n := range nodes {
+		for _, next := range n.Next {
+			fmt.Fprintf(b, "    %s --> %s\n", names[n], names[next])
+		}
+	}
+	return b.String()
+}
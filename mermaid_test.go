@@ -0,0 +1,49 @@
+package dag
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestToMermaid(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mermaid := graph.ToMermaid()
+	if mermaid != graph.ToMermaid() {
+		t.Fatal("expected the same Mermaid output each time")
+	}
+	if !strings.HasPrefix(mermaid, "graph TD\n") {
+		t.Fatalf("expected a top-down flowchart:\n%s", mermaid)
+	}
+
+	// Map each declared name back to the ID in its label.
+	ids := map[string]string{}
+	edges := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(mermaid), "\n")[1:] {
+		line = strings.TrimSpace(line)
+		if from, to, ok := strings.Cut(line, " --> "); ok {
+			edges[ids[from]+"->"+ids[to]] = true
+		} else if name, label, ok := strings.Cut(line, `["`); ok {
+			ids[name] = strings.TrimSuffix(label, `"]`)
+		} else {
+			t.Fatalf("unexpected line %q", line)
+		}
+	}
+	for id, n := range graph {
+		for _, next := range n.Next {
+			if edge := fmt.Sprintf("%s->%s", id, next.ID); !edges[edge] {
+				t.Fatalf("expected the Mermaid output to contain the edge %s:\n%s", edge, mermaid)
+			}
+		}
+	}
+
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if mermaid := graph.ToMermaid(); !strings.Contains(mermaid, `["sum = 5"]`) {
+		t.Fatalf("expected the result of sum in its label:\n%s", mermaid)
+	}
+}
@@ -0,0 +1,176 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ErrFrozen is returned when attempting to modify a Graph after Freeze has been called.
+var ErrFrozen = errors.New("graph is frozen")
+
+// Freeze prevents further modification of the Graph through AddNode, AddEdge, SetEval, and Canonicalize,
+// which will return ErrFrozen instead. It is safe to call Freeze more than once.
+func (g Graph) Freeze() {
+	c := g.structure()
+	structureMu.Lock()
+	defer structureMu.Unlock()
+	c.frozen = true
+}
+
+// structureMu guards the structureCache of every Graph and the Nodes it is shared by.
+var structureMu sync.Mutex
+
+// structureCache holds the Roots and Leaves of a Graph and whether it is frozen. A Graph is a map, so the cache is
+// shared by every Node of the Graph and records the Graph it belongs to and its size; a Node shared with another
+// Graph, or inserted into the map directly, does not find a stale cache. Every method that changes the structure
+// of the Graph clears the cached Roots and Leaves.
+type structureCache struct {
+	owner  uintptr
+	nodes  int
+	frozen bool
+
+	mu            sync.Mutex
+	roots, leaves []*Node
+}
+
+// structure returns the structureCache of the Graph. If the Nodes do not share one that belongs to the Graph,
+// a new one is shared by every Node, which stays frozen if any Node belonged to the frozen Graph.
+func (g Graph) structure() *structureCache {
+	owner := reflect.ValueOf(g).Pointer()
+	structureMu.Lock()
+	defer structureMu.Unlock()
+	for _, n := range g {
+		if c := n.cache; c != nil && c.owner == owner && c.nodes == len(g) {
+			return c
+		}
+		break
+	}
+	c := &structureCache{owner: owner, nodes: len(g)}
+	for _, n := range g {
+		if n.cache != nil && n.cache.owner == owner && n.cache.frozen {
+			c.frozen = true
+		}
+	}
+	for _, n := range g {
+		n.cache = c
+	}
+	return c
+}
+
+// add shares the cache with a Node that was just added to its Graph, and clears the cached Roots and Leaves.
+func (c *structureCache) add(n *Node) {
+	structureMu.Lock()
+	n.cache = c
+	c.nodes++
+	structureMu.Unlock()
+	c.clear()
+}
+
+// clear discards the cached Roots and Leaves after the structure of the Graph changed.
+func (c *structureCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.roots, c.leaves = nil, nil
+}
+
+// Frozen reports whether Freeze has been called on the Graph.
+func (g Graph) Frozen() bool {
+	c := g.structure()
+	structureMu.Lock()
+	defer structureMu.Unlock()
+	return c.frozen
+}
+
+// AddNode adds a new Node with the given ID and EvalFunc and no edges to the Graph.
+// Until it is connected with AddEdge, the Graph is disconnected.
+// If the ID is empty, ErrEmptyID is returned. If the ID is already in use, ErrDuplicateID is returned.
+func (g Graph) AddNode(id string, eval EvalFunc) error {
+	if g.Frozen() {
+		return ErrFrozen
+	}
+	if id == "" {
+		return ErrEmptyID
+	}
+	if _, ok := g[id]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateID, id)
+	}
+	c := g.structure()
+	n := newNode(id, eval)
+	g[id] = n
+	c.add(n)
+	return nil
+}
+
+// AddEdge adds an edge so that the output of the Node with ID "from" is sent to the Node with ID "to".
+// If either Node is not in the Graph, ErrUnknownNode is returned.
+// If the edge already exists, ErrDuplicateEdge is returned.
+// If the edge would create a cycle, ErrCycle is returned and the Graph is unchanged.
+func (g Graph) AddEdge(from, to string) error {
+	if g.Frozen() {
+		return ErrFrozen
+	}
+	for _, id := range []string{from, to} {
+		if _, ok := g[id]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownNode, id)
+		}
+	}
+	if g[from].hasEdgeTo(g[to]) {
+		return fmt.Errorf("%w: %s to %s", ErrDuplicateEdge, from, to)
+	}
+	if g[to].reaches(g[from]) {
+		return fmt.Errorf("edge %s to %s: %w", from, to, ErrCycle)
+	}
+	g[from].connect(g[to])
+	g.structure().clear()
+	return nil
+}
+
+// SetEval replaces the EvalFunc of the Node with the given ID.
+// If the Node is not in the Graph, ErrUnknownNode is returned.
+func (g Graph) SetEval(id string, eval EvalFunc) error {
+	if g.Frozen() {
+		return ErrFrozen
+	}
+	n, ok := g[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownNode, id)
+	}
+	n.eval = eval
+	return nil
+}
+
+// Canonicalize sorts the Next Nodes of every Node in the Graph by ID, so that structurally equal Graphs
+// have identical Next slices regardless of the order their edges were added in.
+// It must not be called while the Graph is being evaluated. If the Graph is frozen, ErrFrozen is returned.
+func (g Graph) Canonicalize() error {
+	if g.Frozen() {
+		return ErrFrozen
+	}
+	for _, n := range g {
+		sort.Slice(n.Next, func(i, j int) bool { return n.Next[i].ID < n.Next[j].ID })
+	}
+	return nil
+}
+
+// reaches reports whether there is a path from the Node to the target, including the empty path.
+func (n *Node) reaches(target *Node) bool {
+	visited := map[*Node]bool{}
+	stack := []*Node{n}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == target {
+			return true
+		}
+		for _, next := range current.Next {
+			if !visited[next] {
+				visited[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return false
+}
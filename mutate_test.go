@@ -0,0 +1,195 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestMutate(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.AddNode("5", Constant(5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.AddEdge("5", "sum"); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.SetEval("max", Min); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.CheckConnectivity(); err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := graph["sum"].Result; result != 9 {
+		t.Fatalf("unexpected result for node sum: want 9 but got %d", result)
+	}
+}
+
+func TestMutateErrors(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.AddNode("sum", Sum); !errors.Is(err, ErrDuplicateID) {
+		t.Fatalf("expected ErrDuplicateID but got %v", err)
+	}
+	if err := graph.AddEdge("1", "nope"); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+	if err := graph.AddEdge("sum", "1"); !errors.Is(err, ErrCycle) {
+		t.Fatalf("expected ErrCycle but got %v", err)
+	}
+	if err := graph.SetEval("nope", Sum); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph.Freeze()
+	if !graph.Frozen() {
+		t.Fatal("expected the graph to be frozen")
+	}
+	if err := graph.AddEdge("1", "min"); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("expected ErrFrozen from AddEdge but got %v", err)
+	}
+	if err := graph.AddNode("5", Constant(5)); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("expected ErrFrozen from AddNode but got %v", err)
+	}
+	if err := graph.SetEval("max", Min); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("expected ErrFrozen from SetEval but got %v", err)
+	}
+	if err := graph.Canonicalize(); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("expected ErrFrozen from Canonicalize but got %v", err)
+	}
+	if len(graph["1"].Next) != 1 {
+		t.Fatal("expected the frozen graph to be unchanged")
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	build := func(targets ...string) (Graph, error) {
+		b := NewGraphBuilder().Node("1", Constant(1)).Node("min", Min).Node("max", Max).Node("sum", Sum)
+		for _, target := range targets {
+			b.Edge("1", target)
+		}
+		return b.Build()
+	}
+	a, err := build("sum", "min", "max")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := build("max", "sum", "min")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(a.ToAdjacencyList()) == fmt.Sprint(b.ToAdjacencyList()) {
+		t.Fatal("expected the graphs to differ in Next order before Canonicalize")
+	}
+	if err := a.Canonicalize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Canonicalize(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(a.ToAdjacencyList()) != fmt.Sprint(b.ToAdjacencyList()) {
+		t.Fatalf("expected identical adjacency lists after Canonicalize: %v and %v", a.ToAdjacencyList(), b.ToAdjacencyList())
+	}
+	if expect := []string{"max", "min", "sum"}; fmt.Sprint(nodeIDs(a["1"].Next)) != fmt.Sprint(expect) {
+		t.Fatalf("unexpected Next order: want %v but got %v", expect, nodeIDs(a["1"].Next))
+	}
+}
+
+func TestFrozenRootsAndLeaves(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph.Freeze()
+	for i := 0; i < 2; i++ {
+		roots := graph.Roots()
+		if len(roots) != 4 {
+			t.Fatalf("expected 4 roots but got %v", nodeIDs(roots))
+		}
+		// Changing the returned slice must not change the cached roots.
+		roots[0] = nil
+		if leaves := graph.Leaves(); len(leaves) != 1 || leaves[0].ID != "sum" {
+			t.Fatalf("expected sum to be the only leaf but got %v", nodeIDs(leaves))
+		}
+	}
+}
+
+func TestRootsAfterMutation(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if roots := graph.Roots(); len(roots) != 4 {
+		t.Fatalf("expected 4 roots but got %v", nodeIDs(roots))
+	}
+	if err := graph.AddNode("5", Constant(5)); err != nil {
+		t.Fatal(err)
+	}
+	if roots := graph.Roots(); len(roots) != 5 {
+		t.Fatalf("expected the added node to be a root but got %v", nodeIDs(roots))
+	}
+	if leaves := graph.Leaves(); len(leaves) != 2 {
+		t.Fatalf("expected the added node to be a leaf but got %v", nodeIDs(leaves))
+	}
+	if err := graph.AddEdge("max", "5"); err != nil {
+		t.Fatal(err)
+	}
+	roots := nodeIDs(graph.Roots())
+	sort.Strings(roots)
+	if fmt.Sprint(roots) != "[1 2 3 4]" {
+		t.Fatalf("expected node 5 to stop being a root after AddEdge but got %v", roots)
+	}
+	if leaves := graph.Leaves(); len(leaves) != 2 {
+		t.Fatalf("expected 2 leaves but got %v", nodeIDs(leaves))
+	}
+
+	// A Node inserted into the map directly neither unfreezes the Graph nor is missing from its roots.
+	graph.Freeze()
+	graph["6"] = NewNode("6", Constant(6))
+	for i := 0; i < 10; i++ {
+		if !graph.Frozen() {
+			t.Fatal("expected the graph to stay frozen")
+		}
+	}
+	if roots := graph.Roots(); len(roots) != 5 {
+		t.Fatalf("expected the inserted node to be a root but got %v", nodeIDs(roots))
+	}
+}
+
+func BenchmarkRoots(b *testing.B) {
+	sum := NewNode("sum", Sum)
+	heads := make([]*Node, 1000)
+	for i := range heads {
+		heads[i] = NewNode(fmt.Sprint(i), Constant(i), sum)
+	}
+	graph, err := New(heads...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("mutable", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			graph.Roots()
+		}
+	})
+	graph.Freeze()
+	b.Run("frozen", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			graph.Roots()
+		}
+	})
+}
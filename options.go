@@ -0,0 +1,245 @@
+package dag
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures the validation performed when constructing a Graph with NewWithOptions.
+type Option func(*config)
+
+// config holds the settings applied by Options.
+type config struct {
+	components  [][]string
+	maxNodes    int
+	idValidator func(id string) error
+	sink        *syntheticNode
+	source      *syntheticNode
+}
+
+// syntheticNode describes a Node added to a Graph by an Option.
+type syntheticNode struct {
+	id   string
+	eval EvalFunc
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithComponents declares groups of Node IDs that form independent components of the Graph.
+// Instead of requiring every Node to be connected, the Nodes of each group must be connected to each other,
+// and every Node must be connected to at least one group.
+func WithComponents(groups [][]string) Option {
+	return func(cfg *config) {
+		cfg.components = groups
+	}
+}
+
+// WithMaxNodes causes ErrTooManyNodes to be returned if the Graph has more than n Nodes.
+// It guards against generated or user-supplied topologies that are larger than expected,
+// and stops traversing the Nodes as soon as the limit is exceeded.
+func WithMaxNodes(n int) Option {
+	return func(cfg *config) {
+		cfg.maxNodes = n
+	}
+}
+
+// WithIDValidator causes the ID of each Node to be passed to validate, and the Graph to be rejected
+// with an error wrapping the returned error if it is not nil.
+// The validator is called in addition to the checks that are always performed, such as uniqueness.
+func WithIDValidator(validate func(id string) error) Option {
+	return func(cfg *config) {
+		cfg.idValidator = validate
+	}
+}
+
+// WithSyntheticSink adds a new Node with the given ID and EvalFunc, and connects every leaf to it,
+// so that the Graph has a single final result. Leaves are determined before the sink is added.
+// If the ID is empty or already used by a Node, ErrEmptyID or ErrDuplicateID is returned.
+func WithSyntheticSink(id string, eval EvalFunc) Option {
+	return func(cfg *config) {
+		cfg.sink = &syntheticNode{id: id, eval: eval}
+	}
+}
+
+// WithSyntheticSource adds a new Node with the given ID and EvalFunc, and connects it to every root,
+// so that its result is received as an input by each of them, for example to seed every root with a common value.
+// Roots are determined before the source is added.
+// If the ID is empty or already used by a Node, ErrEmptyID or ErrDuplicateID is returned.
+func WithSyntheticSource(id string, eval EvalFunc) Option {
+	return func(cfg *config) {
+		cfg.source = &syntheticNode{id: id, eval: eval}
+	}
+}
+
+// EvalOption configures a single call to Evaluate.
+type EvalOption func(*evalConfig)
+
+// evalConfig holds the settings applied by EvalOptions.
+type evalConfig struct {
+	deterministicWorkers bool
+	nodeTimeout          time.Duration
+	maxInFlight          int
+	rejectEmpty          bool
+	levelConcurrency     int
+	maxBufferedInputs    int
+	skip                 []string
+	checkInputOrder      bool
+	watchdog             time.Duration
+	launch               func(func())
+	sink                 func(id string, result int)
+	orderedSink          bool
+
+	// ctx is canceled to stop the evaluation early, as by EvalHandle.Cancel.
+	ctx context.Context
+	// skipped holds the Nodes that are not run because of WithSkip, including descendants of skipped Nodes
+	// whose parents are all skipped.
+	skipped map[*Node]bool
+	// inFlight is a semaphore with a capacity of maxInFlight, or nil if it is unlimited.
+	inFlight chan struct{}
+	// stats collects statistics about the evaluation for EvaluateWithStats, or is nil.
+	stats *EvalStats
+	// edgeLog records the values sent across edges for EvaluateWithInputsLog, or is nil.
+	edgeLog *edgeLog
+	// sinkState tracks the delivery of results to the sink, if one is set.
+	sinkState *sinkState
+	// levelSlots maps each Node to a semaphore with a capacity of levelConcurrency that is shared
+	// by every Node in the same level, or is nil if levels are unlimited.
+	levelSlots map[*Node]chan struct{}
+}
+
+func newEvalConfig(opts []EvalOption) *evalConfig {
+	cfg := &evalConfig{ctx: context.Background(), launch: func(fn func()) { go fn() }}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.maxInFlight > 0 {
+		cfg.inFlight = make(chan struct{}, cfg.maxInFlight)
+	}
+	return cfg
+}
+
+// WithDeterministicWorkers assigns each Node to a worker by its position in the evaluation order modulo the concurrency,
+// instead of letting idle workers take the next Node. A given Graph and concurrency always produce the same
+// worker-to-Node mapping, which keeps logs reproducible across runs.
+func WithDeterministicWorkers() EvalOption {
+	return func(cfg *evalConfig) {
+		cfg.deterministicWorkers = true
+	}
+}
+
+// WithNodeTimeout bounds the evaluation of each individual Node to the given duration.
+// A Node that exceeds it fails with an error wrapping context.DeadlineExceeded that names the Node,
+// and its descendants are not evaluated. Other Nodes are unaffected.
+// The EvalContext.Context passed to an Evaluator is canceled when the timeout expires. An EvalFunc
+// cannot observe it, so a timed out EvalFunc keeps running in the background until it returns,
+// and its result is discarded.
+func WithNodeTimeout(d time.Duration) EvalOption {
+	return func(cfg *evalConfig) {
+		cfg.nodeTimeout = d
+	}
+}
+
+// WithMaxInFlight limits the number of Nodes whose Evaluators run at the same time to n,
+// independently of the number of workers. Workers that are ready to run a Node wait until one of
+// the n slots is free. Streaming Nodes are not limited, since they may wait on parents that need a slot.
+func WithMaxInFlight(n int) EvalOption {
+	return func(cfg *evalConfig) {
+		cfg.maxInFlight = n
+	}
+}
+
+// WithRejectEmpty causes Evaluate to return ErrEmptyGraph for a Graph without any Nodes,
+// which otherwise evaluates successfully.
+func WithRejectEmpty() EvalOption {
+	return func(cfg *evalConfig) {
+		cfg.rejectEmpty = true
+	}
+}
+
+// WithLevelConcurrency limits the number of Nodes whose Evaluators run at the same time within
+// a single level, as partitioned by Graph.Levels, to n. It prevents a wide level from overwhelming
+// a downstream resource without limiting the number of workers. Streaming Nodes are not limited.
+func WithLevelConcurrency(n int) EvalOption {
+	return func(cfg *evalConfig) {
+		cfg.levelConcurrency = n
+	}
+}
+
+// WithMaxBufferedInputs limits the number of inputs buffered for each Node to n, to bound memory when fan-in is huge.
+// A Node that is not Streaming buffers every input before its EvalFunc runs, so if it has more than n inputs,
+// Evaluate returns an error wrapping ErrInputsFull before any Node is evaluated. The buffer of a Streaming Node
+// holds at most n inputs, and parents block while it is full until the Node's EvalFunc consumes them, so its
+// EvalFunc must be a streaming-capable reducer such as Sum that reads inputs as they arrive.
+// Streaming Nodes start consuming on their own goroutines as soon as the evaluation starts, so a parent never blocks
+// on a Streaming Node that no worker has taken yet. Nodes are still dispatched in evaluation order, and the worker
+// that takes a Streaming Node waits for it to finish.
+func WithMaxBufferedInputs(n int) EvalOption {
+	return func(cfg *evalConfig) {
+		cfg.maxBufferedInputs = n
+	}
+}
+
+// WithSkip causes the evaluation to skip the Nodes with the given IDs, which do not run and keep a zero Result,
+// as well as every descendant whose parents are all skipped. A Node with at least one parent that is not
+// skipped still runs, without inputs from its skipped parents, which WithInputOrderCheck leaves out of its
+// InputOrder. The evaluation returns an error wrapping ErrUnknownNode if the Graph does not contain one of the Nodes.
+func WithSkip(ids ...string) EvalOption {
+	return func(cfg *evalConfig) {
+		cfg.skip = append(cfg.skip, ids...)
+	}
+}
+
+// WithInputOrderCheck causes each Node with an InputOrder to fail with ErrInputOrder if its parents' inputs
+// were not sent in that order, instead of silently evaluating a non-commutative EvalFunc with misordered inputs.
+// Inputs arrive in the order parents finish, which is not guaranteed even with a concurrency of 1, so a failed
+// check does not mean the Graph is wrong; it prevents a wrong result from being used. Injected inputs and
+// Streaming Nodes are not checked.
+func WithInputOrderCheck() EvalOption {
+	return func(cfg *evalConfig) {
+		cfg.checkInputOrder = true
+	}
+}
+
+// WithWatchdog causes Evaluate to return an error wrapping ErrDeadlockSuspected if no Node completes within
+// the interval, instead of hanging forever when Nodes wait for inputs that never arrive, for example because
+// of mismatched wait counts. The error lists the Nodes that have not completed and the number of inputs each
+// is still waiting for. The interval must be longer than the slowest Node takes to evaluate.
+// When the watchdog fires, workers that are still blocked are abandoned.
+func WithWatchdog(interval time.Duration) EvalOption {
+	return func(cfg *evalConfig) {
+		cfg.watchdog = interval
+	}
+}
+
+// WithGoroutineLauncher sets the function used to start each worker goroutine, in place of the go statement,
+// for example to run workers in a managed pool or to wrap them with a panic handler. The launcher must run
+// the given function concurrently and must not wait for it to return.
+func WithGoroutineLauncher(launch func(func())) EvalOption {
+	return func(cfg *evalConfig) {
+		cfg.launch = launch
+	}
+}
+
+// WithResultSink calls sink with the ID and Result of each Node as soon as it is evaluated successfully.
+// Calls are made from the evaluation's workers, one at a time, and delay the worker until sink returns.
+// Nodes that fail or are skipped are not reported.
+func WithResultSink(sink func(id string, result int)) EvalOption {
+	return func(cfg *evalConfig) {
+		cfg.sink = sink
+	}
+}
+
+// WithOrderedSink causes the sink set by WithResultSink to be called in evaluation order, which is a
+// topological order, instead of in the order Nodes complete. The result of a Node is held back until every
+// Node before it in the order has completed, so a slow Node delays the delivery of later results.
+func WithOrderedSink() EvalOption {
+	return func(cfg *evalConfig) {
+		cfg.orderedSink = true
+	}
+}
@@ -0,0 +1,67 @@
+package dag
+
+import "fmt"
+
+// CountPaths returns the number of distinct directed paths from the Node with ID "from" to the Node with ID "to".
+// A Node has exactly one path to itself. Counts are accumulated in an int and wrap around on overflow,
+// which is possible for graphs with many stacked diamonds.
+// If either Node is not in the Graph, ErrUnknownNode is returned.
+func (g Graph) CountPaths(from, to string) (int, error) {
+	for _, id := range []string{from, to} {
+		if _, ok := g[id]; !ok {
+			return 0, fmt.Errorf("%w: %s", ErrUnknownNode, id)
+		}
+	}
+	sorted, err := g.TopologicalSort()
+	if err != nil {
+		return 0, fmt.Errorf("topological sort: %w", err)
+	}
+
+	// Each Node passes its path count on to the Nodes that depend on it, in topological order.
+	paths := map[string]int{from: 1}
+	for _, node := range sorted {
+		for _, next := range node.Next {
+			paths[next.ID] += paths[node.ID]
+		}
+	}
+	return paths[to], nil
+}
+
+// CriticalPath returns the path from a root to a leaf with the highest total Node.Cost, along with that total.
+// Since evaluating a Node must wait for every Node before it on any path, the critical path bounds the
+// evaluation time of the Graph no matter the concurrency. If several paths tie, the first one found is returned.
+// An empty Graph has an empty critical path.
+func (g Graph) CriticalPath() ([]*Node, int, error) {
+	sorted, err := g.TopologicalSort()
+	if err != nil {
+		return nil, 0, fmt.Errorf("topological sort: %w", err)
+	}
+
+	// Visit Nodes in topological order, recording the costliest path that ends at each Node
+	// by the total cost and the previous Node on that path.
+	total := make(map[*Node]int, len(sorted))
+	prev := make(map[*Node]*Node, len(sorted))
+	var end *Node
+	for _, node := range sorted {
+		total[node] += node.Cost
+		if end == nil || total[node] > total[end] {
+			end = node
+		}
+		for _, next := range node.Next {
+			if _, ok := prev[next]; !ok || total[node] > total[next] {
+				total[next] = total[node]
+				prev[next] = node
+			}
+		}
+	}
+	if end == nil {
+		return []*Node{}, 0, nil
+	}
+
+	// Follow the previous Nodes back to the root.
+	path := []*Node{}
+	for node := end; node != nil; node = prev[node] {
+		path = append([]*Node{node}, path...)
+	}
+	return path, total[end], nil
+}
@@ -0,0 +1,68 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCountPaths(t *testing.T) {
+	// Two paths from "1" to "sum": via "min" and via "max".
+	sum := NewNode("sum", Sum)
+	graph, err := New(NewNode("1", Constant(1), NewNode("min", Min, sum), NewNode("max", Max, sum)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		From, To string
+		Expect   int
+	}{
+		{From: "1", To: "sum", Expect: 2},
+		{From: "1", To: "min", Expect: 1},
+		{From: "sum", To: "sum", Expect: 1},
+		{From: "sum", To: "1", Expect: 0},
+		{From: "min", To: "max", Expect: 0},
+	} {
+		count, err := graph.CountPaths(test.From, test.To)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != test.Expect {
+			t.Fatalf("unexpected path count from %s to %s: want %d but got %d", test.From, test.To, test.Expect, count)
+		}
+	}
+	if _, err := graph.CountPaths("1", "nope"); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+}
+
+func TestCriticalPath(t *testing.T) {
+	// The longest chain by edge count is 1 -> a -> b -> sum, but "slow" is far more costly.
+	sum := NewNode("sum", Sum)
+	slow := NewNode("slow", Sum, sum)
+	slow.Cost = 10
+	graph, err := New(NewNode("1", Constant(1), slow, NewNode("a", Sum, NewNode("b", Sum, sum))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, cost, err := graph.CriticalPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []string{"1", "slow", "sum"}; fmt.Sprint(nodeIDs(path)) != fmt.Sprint(expect) {
+		t.Fatalf("unexpected critical path: want %v but got %v", expect, nodeIDs(path))
+	}
+	if cost != 12 {
+		t.Fatalf("unexpected critical path cost: want 12 but got %d", cost)
+	}
+
+	// With the default cost of 1, the longest chain is the critical path.
+	slow.Cost = 1
+	path, cost, err = graph.CriticalPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []string{"1", "a", "b", "sum"}; fmt.Sprint(nodeIDs(path)) != fmt.Sprint(expect) || cost != 4 {
+		t.Fatalf("unexpected critical path: want %v with cost 4 but got %v with cost %d", expect, nodeIDs(path), cost)
+	}
+}
@@ -0,0 +1,105 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrNotReady is returned when a Node is used before all of its parents have completed.
+var ErrNotReady = errors.New("node not ready")
+
+// ErrAlreadyEvaluated is returned when completing a Node that has already been evaluated.
+var ErrAlreadyEvaluated = errors.New("node already evaluated")
+
+// Ready returns the Nodes that have not been evaluated but whose parents all have, sorted by ID.
+// Together with Compute and MarkComplete, it allows a Graph to be evaluated by an external scheduler
+// instead of Evaluate: repeatedly take the Ready Nodes, Compute each, and MarkComplete it with the result.
+func (g Graph) Ready() []*Node {
+	parents := g.parents()
+	ready := g.Filter(func(n *Node) bool {
+		if n.evaluated.Load() {
+			return false
+		}
+		for _, parent := range parents[n] {
+			if !parent.evaluated.Load() {
+				return false
+			}
+		}
+		return true
+	})
+	sort.Slice(ready, func(i, j int) bool { return ready[i].ID < ready[j].ID })
+	return ready
+}
+
+// Compute runs the Evaluator of the Ready Node with the given ID on the inputs received from its parents,
+// and returns the result. It does not complete the Node; call MarkComplete with the result to do so.
+// Compute may only be called once per Node.
+// If the Graph does not contain the Node, ErrUnknownNode is returned.
+// If the Node is not Ready, ErrNotReady is returned.
+// If the Node was already computed or evaluated, ErrAlreadyEvaluated is returned.
+func (g Graph) Compute(id string) (int, error) {
+	n, ok := g[id]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownNode, id)
+	}
+	for _, parent := range g.parents()[n] {
+		if !parent.evaluated.Load() {
+			return 0, fmt.Errorf("%w: %s", ErrNotReady, id)
+		}
+	}
+	if n.evaluated.Load() || !n.started.CompareAndSwap(false, true) {
+		return 0, fmt.Errorf("%w: %s", ErrAlreadyEvaluated, id)
+	}
+	closeInputs(n.inputs, n.anyInputs)
+	result, err := n.run(newEvalConfig(nil))
+	if err == nil {
+		err = n.validate(result)
+	}
+	if err != nil {
+		return 0, err
+	}
+	output, _ := result.(int)
+	return output, nil
+}
+
+// MarkComplete records the result of the Node with the given ID and sends it to each of the Node's Next Nodes,
+// as if the Node had been evaluated. It is equivalent to SetResult.
+// If the Graph does not contain the Node, ErrUnknownNode is returned.
+// If the Node was already evaluated, ErrAlreadyEvaluated is returned.
+func (g Graph) MarkComplete(id string, result int) error {
+	return g.SetResult(id, result)
+}
+
+// SetResult records the result of the Node with the given ID and sends it to each of the Node's Next Nodes,
+// which count it as one of their inputs, as if the Node had been evaluated. It lets code outside of Evaluate
+// complete Nodes, and may be called concurrently: if several calls complete the same Node, only one succeeds.
+// It must not be called for a Node that Evaluate may evaluate.
+// If the Graph does not contain the Node, ErrUnknownNode is returned.
+// If the Node was already evaluated or completed, ErrAlreadyEvaluated is returned.
+func (g Graph) SetResult(id string, value int) error {
+	n, ok := g[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownNode, id)
+	}
+	if n.evaluated.Load() || !n.completed.CompareAndSwap(false, true) {
+		return fmt.Errorf("%w: %s", ErrAlreadyEvaluated, id)
+	}
+	n.setResult(value)
+	n.evaluated.Store(true)
+	for _, next := range n.Next {
+		next.receive(n)
+	}
+	return nil
+}
+
+// parents returns the parents of each Node in the Graph.
+func (g Graph) parents() map[*Node][]*Node {
+	parents := make(map[*Node][]*Node, len(g))
+	for _, n := range g {
+		for _, next := range n.Next {
+			parents[next] = append(parents[next], n)
+		}
+	}
+	return parents
+}
@@ -0,0 +1,124 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestManualScheduling(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Drive the evaluation one round of Ready Nodes at a time.
+	rounds := [][]string{}
+	for ready := graph.Ready(); len(ready) > 0; ready = graph.Ready() {
+		rounds = append(rounds, nodeIDs(ready))
+		for _, node := range ready {
+			result, err := graph.Compute(node.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := graph.MarkComplete(node.ID, result); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	expectRounds := [][]string{{"1", "2", "3", "4"}, {"max", "min"}, {"sum"}}
+	if fmt.Sprint(rounds) != fmt.Sprint(expectRounds) {
+		t.Fatalf("unexpected ready rounds: want %v but got %v", expectRounds, rounds)
+	}
+	for id, expect := range map[string]int{"max": 2, "min": 3, "sum": 5} {
+		if result, err := graph.Result(id); err != nil || result != expect {
+			t.Fatalf("unexpected result for node %s: want %d but got %d (%v)", id, expect, result, err)
+		}
+	}
+}
+
+func TestManualSchedulingErrors(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := graph.Compute("sum"); !errors.Is(err, ErrNotReady) {
+		t.Fatalf("expected ErrNotReady but got %v", err)
+	}
+	if _, err := graph.Compute("nope"); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+	if _, err := graph.Compute("2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := graph.Compute("2"); !errors.Is(err, ErrAlreadyEvaluated) {
+		t.Fatalf("expected ErrAlreadyEvaluated computing a node twice but got %v", err)
+	}
+	if err := graph.MarkComplete("1", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := graph.Compute("1"); !errors.Is(err, ErrAlreadyEvaluated) {
+		t.Fatalf("expected ErrAlreadyEvaluated computing a completed node but got %v", err)
+	}
+	if err := graph.MarkComplete("1", 1); !errors.Is(err, ErrAlreadyEvaluated) {
+		t.Fatalf("expected ErrAlreadyEvaluated but got %v", err)
+	}
+	if err := graph.MarkComplete("nope", 1); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+}
+
+func TestComputeAfterEvaluate(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"1", "sum"} {
+		if _, err := graph.Compute(id); !errors.Is(err, ErrAlreadyEvaluated) {
+			t.Fatalf("expected ErrAlreadyEvaluated computing node %s after Evaluate but got %v", id, err)
+		}
+	}
+}
+
+func TestSetResult(t *testing.T) {
+	graph, err := New(NewNode("root", Constant(1), NewNode("double", func(inputs chan int) int {
+		return 2 * Sum(inputs)
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Complete the root from several goroutines at once; only one call may succeed.
+	errs := make(chan error, 10)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			errs <- graph.SetResult("root", 21)
+		}()
+	}
+	succeeded := 0
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err == nil {
+			succeeded++
+		} else if !errors.Is(err, ErrAlreadyEvaluated) {
+			t.Fatal(err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("expected exactly 1 call to succeed but got %d", succeeded)
+	}
+
+	result, err := graph.Compute("double")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != 42 {
+		t.Fatalf("unexpected result for node double: want 42 but got %d", result)
+	}
+	if err := graph.SetResult("nope", 1); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package dag
+
+import "sync"
+
+// sinkState delivers the results of completed Nodes to the sink of an evaluation.
+type sinkState struct {
+	mu sync.Mutex
+	// position maps each Node to its index in the evaluation order, if delivery is ordered.
+	position map[*Node]int
+	// completed records the Nodes at each position that have completed but were not delivered yet.
+	completed map[int]*Node
+	// next is the position of the next Node to deliver.
+	next int
+}
+
+// startSink prepares the delivery of results for the Nodes, which must be in evaluation order.
+func (cfg *evalConfig) startSink(nodes []*Node) {
+	if cfg.sink == nil {
+		return
+	}
+	cfg.sinkState = &sinkState{}
+	if cfg.orderedSink {
+		cfg.sinkState.position = make(map[*Node]int, len(nodes))
+		cfg.sinkState.completed = make(map[int]*Node, len(nodes))
+		for i, n := range nodes {
+			cfg.sinkState.position[n] = i
+		}
+	}
+}
+
+// complete records that the Node completed, whether or not it was evaluated successfully,
+// and delivers every result that is ready to the sink.
+func (cfg *evalConfig) complete(n *Node) {
+	s := cfg.sinkState
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.position == nil {
+		cfg.deliver(n)
+		return
+	}
+	s.completed[s.position[n]] = n
+	for {
+		next, ok := s.completed[s.next]
+		if !ok {
+			return
+		}
+		delete(s.completed, s.next)
+		s.next++
+		cfg.deliver(next)
+	}
+}
+
+// deliver calls the sink with the Node's Result if it was evaluated successfully.
+func (cfg *evalConfig) deliver(n *Node) {
+	if n.evaluated.Load() {
+		cfg.sink(n.ID, n.Result)
+	}
+}
@@ -0,0 +1,85 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// slowFirstGraph returns a Graph of 8 constants from 0 to 7 connected to the "sum" Node,
+// where lower constants take longer to evaluate so that Nodes complete out of order.
+func slowFirstGraph() (Graph, error) {
+	sum := NewNode("sum", Sum)
+	heads := make([]*Node, 8)
+	for i := range heads {
+		i := i
+		heads[i] = NewNode(fmt.Sprint(i), func(_ chan int) int {
+			time.Sleep(time.Duration(8-i) * time.Millisecond)
+			return i
+		}, sum)
+	}
+	return New(heads...)
+}
+
+func TestResultSink(t *testing.T) {
+	graph, err := slowFirstGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := map[string]int{}
+	err = graph.Evaluate(8, WithResultSink(func(id string, result int) {
+		results[id] = result
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(graph) {
+		t.Fatalf("expected %d results but got %d", len(graph), len(results))
+	}
+	for id, n := range graph {
+		if results[id] != n.Result {
+			t.Fatalf("unexpected result for node %s: want %d but got %d", id, n.Result, results[id])
+		}
+	}
+}
+
+func TestOrderedSink(t *testing.T) {
+	graph, err := slowFirstGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivered := []string{}
+	err = graph.Evaluate(8, WithOrderedSink(), WithResultSink(func(id string, _ int) {
+		delivered = append(delivered, id)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := graph.TopologicalSort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(delivered) != fmt.Sprint(nodeIDs(order)) {
+		t.Fatalf("unexpected delivery order: want %v but got %v", nodeIDs(order), delivered)
+	}
+}
+
+func TestOrderedSinkSkipsFailedNodes(t *testing.T) {
+	errBoom := errors.New("boom")
+	fail := NewEvaluatorNode("fail", failing{errBoom}, NewNode("skipped", Sum))
+	graph, err := New(NewNode("1", Constant(1), fail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivered := []string{}
+	err = graph.Evaluate(2, WithOrderedSink(), WithResultSink(func(id string, _ int) {
+		delivered = append(delivered, id)
+	}))
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected the node to fail but got %v", err)
+	}
+	if fmt.Sprint(delivered) != "[1]" {
+		t.Fatalf("expected only node 1 to be delivered but got %v", delivered)
+	}
+}
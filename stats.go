@@ -0,0 +1,79 @@
+package dag
+
+import (
+	"fmt"
+	"time"
+)
+
+// Stats summarizes the shape of a Graph.
+type Stats struct {
+	NodeCount    int
+	EdgeCount    int
+	RootCount    int
+	LeafCount    int
+	MaxDepth     int // Number of edges on the longest path from a root to a leaf.
+	MaxIndegree  int
+	MaxOutdegree int
+}
+
+// String returns the Stats in a single line suitable for logging.
+func (s Stats) String() string {
+	return fmt.Sprintf("nodes=%d edges=%d roots=%d leaves=%d depth=%d indegree=%d outdegree=%d",
+		s.NodeCount, s.EdgeCount, s.RootCount, s.LeafCount, s.MaxDepth, s.MaxIndegree, s.MaxOutdegree)
+}
+
+// Stats returns a summary of the shape of the Graph.
+func (g Graph) Stats() (Stats, error) {
+	sorted, err := g.TopologicalSort()
+	if err != nil {
+		return Stats{}, fmt.Errorf("topological sort: %w", err)
+	}
+	s := Stats{
+		NodeCount: len(g),
+		RootCount: len(g.Roots()),
+		LeafCount: len(g.Leaves()),
+	}
+
+	// Visit Nodes in topological order so that each Node's depth is final before it is passed on.
+	depth := make(map[*Node]int, len(g))
+	indegree := make(map[*Node]int, len(g))
+	for _, node := range sorted {
+		s.EdgeCount += len(node.Next)
+		if len(node.Next) > s.MaxOutdegree {
+			s.MaxOutdegree = len(node.Next)
+		}
+		if depth[node] > s.MaxDepth {
+			s.MaxDepth = depth[node]
+		}
+		for _, next := range node.Next {
+			if indegree[next]++; indegree[next] > s.MaxIndegree {
+				s.MaxIndegree = indegree[next]
+			}
+			if depth[node]+1 > depth[next] {
+				depth[next] = depth[node] + 1
+			}
+		}
+	}
+	return s, nil
+}
+
+// EvalStats describes the work done during an evaluation by EvaluateWithStats.
+type EvalStats struct {
+	Workers []WorkerStats // Indexed by worker.
+	Elapsed time.Duration // Wall-clock time of the whole evaluation.
+}
+
+// WorkerStats describes the work done by a single worker during an evaluation.
+type WorkerStats struct {
+	Nodes int           // Number of Nodes taken from the queue, including skipped and failed Nodes.
+	Busy  time.Duration // Time spent running Evaluators, excluding waiting for inputs or slots.
+}
+
+// Nodes returns the number of Nodes taken from the queue by all workers.
+func (s EvalStats) Nodes() int {
+	total := 0
+	for _, w := range s.Workers {
+		total += w.Nodes
+	}
+	return total
+}
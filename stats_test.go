@@ -0,0 +1,48 @@
+package dag
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := graph.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := Stats{
+		NodeCount:    7,
+		EdgeCount:    6,
+		RootCount:    4,
+		LeafCount:    1,
+		MaxDepth:     2,
+		MaxIndegree:  2,
+		MaxOutdegree: 1,
+	}
+	if stats != expect {
+		t.Fatalf("unexpected stats: want %s but got %s", expect, stats)
+	}
+}
+
+func TestEvaluateWithStats(t *testing.T) {
+	graph, err := New(NewNode("1", Constant(1), NewNode("2", Sum, NewNode("3", Sum, NewNode("4", Sum)))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := graph.EvaluateWithStats(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Workers) != 2 {
+		t.Fatalf("expected stats for 2 workers but got %d", len(stats.Workers))
+	}
+	if nodes := stats.Nodes(); nodes != len(graph) {
+		t.Fatalf("expected %d nodes to be processed but got %d", len(graph), nodes)
+	}
+	for i, w := range stats.Workers {
+		if w.Busy > stats.Elapsed {
+			t.Fatalf("worker %d was busy for %s, longer than the evaluation took (%s)", i, w.Busy, stats.Elapsed)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package dag
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// Dimensions of the SVG layout, in pixels.
+const (
+	svgNodeWidth  = 120
+	svgNodeHeight = 40
+	svgGapX       = 20
+	svgGapY       = 40
+	svgMargin     = 10
+)
+
+// RenderSVG returns a self-contained SVG image of the Graph. Each level of the Graph, as partitioned
+// by Levels, is drawn as a row of boxes from top to bottom, with arrows for the edges between them.
+// Each box shows the Node's ID and, if the Node has been evaluated, its Result.
+func (g Graph) RenderSVG() ([]byte, error) {
+	levels, err := g.Levels()
+	if err != nil {
+		return nil, err
+	}
+
+	// Place each Node by its level and its position within the level.
+	type point struct{ x, y int }
+	positions := make(map[*Node]point, len(g))
+	width, height := 2*svgMargin, 2*svgMargin
+	for i, level := range levels {
+		for j, n := range level {
+			positions[n] = point{
+				x: svgMargin + j*(svgNodeWidth+svgGapX),
+				y: svgMargin + i*(svgNodeHeight+svgGapY),
+			}
+		}
+		if w := 2*svgMargin + len(level)*(svgNodeWidth+svgGapX) - svgGapX; w > width {
+			width = w
+		}
+	}
+	if len(levels) > 0 {
+		height += len(levels)*(svgNodeHeight+svgGapY) - svgGapY
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, height, width, height)
+	buf.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto">` +
+		`<path d="M 0 0 L 10 5 L 0 10 z"/></marker></defs>` + "\n")
+
+	for _, n := range g.SortedNodes() {
+		from := positions[n]
+		for _, next := range n.Next {
+			to := positions[next]
+			fmt.Fprintf(buf, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black" marker-end="url(#arrow)"/>`+"\n",
+				from.x+svgNodeWidth/2, from.y+svgNodeHeight, to.x+svgNodeWidth/2, to.y)
+		}
+	}
+	for _, n := range g.SortedNodes() {
+		p := positions[n]
+		label := n.ID
+		if n.evaluated.Load() {
+			label = fmt.Sprintf("%s = %d", n.ID, n.Result)
+		}
+		fmt.Fprintf(buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="white" stroke="black"/>`+"\n",
+			p.x, p.y, svgNodeWidth, svgNodeHeight)
+		fmt.Fprintf(buf, `<text x="%d" y="%d" text-anchor="middle" dominant-baseline="middle" font-family="sans-serif" font-size="12">`,
+			p.x+svgNodeWidth/2, p.y+svgNodeHeight/2)
+		if err := xml.EscapeText(buf, []byte(label)); err != nil {
+			return nil, err
+		}
+		buf.WriteString("</text>\n")
+	}
+	buf.WriteString("</svg>\n")
+	return buf.Bytes(), nil
+}
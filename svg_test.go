@@ -0,0 +1,45 @@
+package dag
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestRenderSVG(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	svg, err := graph.RenderSVG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Collect the text of every element, which must be well-formed XML.
+	texts := map[string]bool{}
+	decoder := xml.NewDecoder(bytes.NewReader(svg))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid XML: %v\n%s", err, svg)
+		}
+		if data, ok := token.(xml.CharData); ok {
+			texts[string(bytes.TrimSpace(data))] = true
+		}
+	}
+	for id, n := range graph {
+		if label := fmt.Sprintf("%s = %d", id, n.Result); !texts[label] {
+			t.Fatalf("expected the SVG to contain %q:\n%s", label, svg)
+		}
+	}
+}
@@ -1,31 +1,87 @@
 package dag
 
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // TopologicalSort returns a slice containing every Node in the Graph sorted in an order
 // which guarantees that each node is placed after any Nodes that it depends upon in the Graph.
 // If a cycle is detected during iteration, ErrCycle is returned.
+// If some Nodes can't be reached from any root, for example because they are only reachable through a cycle
+// or were orphaned by removing edges directly, an error wrapping ErrUnreachable and listing their IDs is returned.
 func (g Graph) TopologicalSort() ([]*Node, error) {
-	s := &topologicalSort{
-		visiting: make(map[*Node]struct{}),
-		visited:  make(map[*Node]struct{}),
-		sorted:   make([]*Node, 0),
-	}
+	s := newTopologicalSort()
 
 	// Begin topological sorting by visiting each Node with indegree 0 (roots).
-	for _, node := range g.Roots() {
+	// Roots are visited in order of ID so that the result does not depend on map iteration order.
+	roots := g.Roots()
+	sort.Slice(roots, func(i, j int) bool { return roots[i].ID < roots[j].ID })
+	for _, node := range roots {
 		if err := s.visit(node); err != nil {
 			return nil, err
 		}
 	}
 
+	// Check that no Node was omitted because it could not be reached from a root.
+	omitted := []string{}
+	for id, n := range g {
+		if _, ok := s.visited[n]; !ok {
+			omitted = append(omitted, id)
+		}
+	}
+	if len(omitted) > 0 {
+		sort.Strings(omitted)
+		return nil, fmt.Errorf("%w: %s", ErrUnreachable, strings.Join(omitted, ", "))
+	}
+
 	// Return a slice containing Nodes in topological order.
 	return s.sorted, nil
 }
 
+// ErrUnreachable is returned when some Nodes of a Graph can't be reached from any root.
+var ErrUnreachable = errors.New("unreachable nodes")
+
+// HasCycle reports whether a cycle is reachable from any of the given Nodes.
+// Unlike New, it does not require the Nodes to form a connected Graph.
+func HasCycle(nodes ...*Node) bool {
+	s := newTopologicalSort()
+	for _, node := range nodes {
+		if err := s.visit(node); err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertAcyclic returns ErrCycle if the Graph currently contains a cycle, for example after its edges were
+// changed directly. Unlike TopologicalSort, it also detects cycles that can't be reached from any root.
+func (g Graph) AssertAcyclic() error {
+	s := newTopologicalSort()
+	for _, node := range g.SortedNodes() {
+		if err := s.visit(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type topologicalSort struct {
 	visiting, visited map[*Node]struct{}
 	sorted            []*Node
 }
 
+func newTopologicalSort() *topologicalSort {
+	return &topologicalSort{
+		visiting: make(map[*Node]struct{}),
+		visited:  make(map[*Node]struct{}),
+		sorted:   make([]*Node, 0),
+	}
+}
+
 func (s *topologicalSort) prependToSorted(n *Node) {
 	s.sorted = append([]*Node{n}, s.sorted...)
 }
@@ -46,7 +102,9 @@ func (s *topologicalSort) visit(node *Node) error {
 
 	// Visit each "next" node (nodes that depend on this one).
 	for _, next := range node.Next {
-		s.visit(next)
+		if err := s.visit(next); err != nil {
+			return err
+		}
 	}
 
 	// Unmark the node as visiting.
@@ -61,6 +119,69 @@ func (s *topologicalSort) visit(node *Node) error {
 	return nil
 }
 
+// prioritize reorders a topologically sorted slice of Nodes so that whenever more than one Node is ready
+// (all of its parents appear earlier in the slice), the ready Node with the highest Priority comes next.
+// Ties are broken by the Node's position in the original slice, so the result is still a topological ordering.
+func prioritize(sorted []*Node) []*Node {
+	index := make(map[*Node]int, len(sorted))
+	for i, node := range sorted {
+		index[node] = i
+	}
+
+	// Count the parents of each Node that have not been placed yet.
+	waiting := make(map[*Node]int, len(sorted))
+	for _, node := range sorted {
+		for _, next := range node.Next {
+			waiting[next]++
+		}
+	}
+
+	ready := &readyQueue{index: index}
+	for _, node := range sorted {
+		if waiting[node] == 0 {
+			heap.Push(ready, node)
+		}
+	}
+
+	out := make([]*Node, 0, len(sorted))
+	for ready.Len() > 0 {
+		node := heap.Pop(ready).(*Node)
+		out = append(out, node)
+		for _, next := range node.Next {
+			if waiting[next]--; waiting[next] == 0 {
+				heap.Push(ready, next)
+			}
+		}
+	}
+	return out
+}
+
+// readyQueue is a heap of Nodes ordered by descending Priority, then by ascending topological index.
+type readyQueue struct {
+	nodes []*Node
+	index map[*Node]int
+}
+
+func (q readyQueue) Len() int { return len(q.nodes) }
+
+func (q readyQueue) Less(i, j int) bool {
+	a, b := q.nodes[i], q.nodes[j]
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return q.index[a] < q.index[b]
+}
+
+func (q readyQueue) Swap(i, j int) { q.nodes[i], q.nodes[j] = q.nodes[j], q.nodes[i] }
+
+func (q *readyQueue) Push(x any) { q.nodes = append(q.nodes, x.(*Node)) }
+
+func (q *readyQueue) Pop() any {
+	last := q.nodes[len(q.nodes)-1]
+	q.nodes = q.nodes[:len(q.nodes)-1]
+	return last
+}
+
 func nodeIDs(nodes []*Node) []string {
 	out := make([]string, len(nodes))
 	for i, node := range nodes {
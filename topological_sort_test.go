@@ -3,6 +3,7 @@ package dag
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -109,3 +110,74 @@ func TestTopologicalSort(t *testing.T) {
 		})
 	}
 }
+
+func TestHasCycle(t *testing.T) {
+	a, b := NewNode("a", Constant(1)), NewNode("b", Constant(2))
+	a.Next = append(a.Next, b)
+	b.Next = append(b.Next, a)
+	if !HasCycle(a, b) {
+		t.Fatal("expected a cycle between a and b")
+	}
+
+	sum := NewNode("sum", Sum)
+	max := NewNode("max", Max, sum)
+	min := NewNode("min", Min, sum)
+	heads := []*Node{
+		NewNode("1", Constant(1), max),
+		NewNode("2", Constant(2), max),
+		NewNode("3", Constant(3), min),
+		NewNode("4", Constant(4), min),
+	}
+	if HasCycle(heads...) {
+		t.Fatal("expected no cycle in the assignment graph")
+	}
+
+	// Disconnected Nodes are allowed.
+	if HasCycle(NewNode("x", Constant(1)), NewNode("y", Constant(2))) {
+		t.Fatal("expected no cycle in disconnected nodes")
+	}
+}
+
+func TestTopologicalSortUnreachable(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add a cycle without roots, which no walk from the existing roots reaches.
+	a, b := newNode("a", EvalFunc(Sum)), newNode("b", EvalFunc(Sum))
+	a.connect(b)
+	b.connect(a)
+	graph["a"], graph["b"] = a, b
+
+	_, err = graph.TopologicalSort()
+	if !errors.Is(err, ErrUnreachable) {
+		t.Fatalf("expected ErrUnreachable but got %v", err)
+	}
+	if !strings.HasSuffix(err.Error(), ": a, b") {
+		t.Fatalf("expected the error to list the omitted nodes but got %q", err)
+	}
+
+	// Nodes orphaned by removing their edges directly are reported too.
+	delete(graph, "a")
+	delete(graph, "b")
+	graph["1"].Next = nil
+	graph["2"].Next = nil
+	if _, err := graph.TopologicalSort(); err == nil || !strings.HasSuffix(err.Error(), ": max") {
+		t.Fatalf("expected the error to list node max but got %v", err)
+	}
+}
+
+func TestAssertAcyclic(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.AssertAcyclic(); err != nil {
+		t.Fatal(err)
+	}
+	graph["sum"].Next = append(graph["sum"].Next, graph["max"])
+	if err := graph.AssertAcyclic(); !errors.Is(err, ErrCycle) {
+		t.Fatalf("expected ErrCycle but got %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Relabel returns a new Graph in which each Node ID that is a key of mapping is replaced by its value.
+// Nodes that are not in mapping keep their IDs, and edges follow the renamed Nodes.
+// The returned Graph has not been evaluated, and the original Graph is unchanged.
+// If mapping contains an ID that is not in the Graph, ErrUnknownNode is returned.
+// If the new IDs are not unique, ErrDuplicateID is returned, and if one is empty, ErrEmptyID is returned.
+func (g Graph) Relabel(mapping map[string]string) (Graph, error) {
+	for from := range mapping {
+		if _, ok := g[from]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNode, from)
+		}
+	}
+	return g.copyGraph(func(id string) string {
+		if to, ok := mapping[id]; ok {
+			return to
+		}
+		return id
+	})
+}
+
+// Clone returns a new Graph with a copy of every Node and edge. Each copy has the Node's Evaluator, Metadata,
+// Priority, Streaming, Cost, InputOrder, InputTransform, and OutputValidator, as well as the inputs injected with
+// Inject that the Node has not consumed yet; inputs injected before an earlier evaluation are not copied.
+// The copy has not been evaluated, so it can be evaluated even if the original already was.
+func (g Graph) Clone() Graph {
+	// Keeping every ID can't produce duplicates, so there is no error to handle.
+	clone, _ := g.copyGraph(func(id string) string { return id })
+	return clone
+}
+
+// copyGraph returns a new Graph with a copy of every Node and edge, with each Node ID passed through rename.
+// If the renamed IDs are not unique, ErrDuplicateID is returned.
+func (g Graph) copyGraph(rename func(id string) string) (Graph, error) {
+	copies := make(map[*Node]*Node, len(g))
+	out := make(Graph, len(g))
+	for _, node := range g.SortedNodes() {
+		c := node.copyWithoutEdges()
+		c.ID = rename(node.ID)
+		if c.ID == "" {
+			return nil, ErrEmptyID
+		}
+		for i, id := range c.InputOrder {
+			c.InputOrder[i] = rename(id)
+		}
+		if _, ok := out[c.ID]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateID, c.ID)
+		}
+		out[c.ID] = c
+		copies[node] = c
+	}
+	for _, node := range g.SortedNodes() {
+		for _, next := range node.Next {
+			copies[node].connect(copies[next])
+		}
+	}
+	return out, nil
+}
+
+// SharedPartition is the key of the partition returned by PartitionByRoot that holds the Nodes
+// reachable from more than one root.
+const SharedPartition = ""
+
+// PartitionByRoot returns, for each root ID, a new Graph with a copy of every Node that is reachable
+// from that root and no other, including the root itself. Nodes reachable from more than one root
+// are copied to the partition keyed by SharedPartition, which is omitted if there are none.
+// Only edges between Nodes of the same partition are copied, and the partitions are not validated.
+// For a forest, each partition is a tree that can be evaluated independently.
+func (g Graph) PartitionByRoot() map[string]Graph {
+	owners := make(map[*Node][]string, len(g))
+	for _, root := range g.Roots() {
+		g.WalkFrom(root.ID, func(current *Node, prev []*Node) error {
+			if owned := owners[current]; len(owned) > 0 && owned[len(owned)-1] == root.ID {
+				return ErrSkipChildren
+			}
+			owners[current] = append(owners[current], root.ID)
+			return nil
+		})
+	}
+
+	partitions := make(map[string]map[*Node]bool)
+	for n, owned := range owners {
+		key := owned[0]
+		if len(owned) > 1 {
+			key = SharedPartition
+		}
+		if partitions[key] == nil {
+			partitions[key] = make(map[*Node]bool)
+		}
+		partitions[key][n] = true
+	}
+	out := make(map[string]Graph, len(partitions))
+	for key, nodes := range partitions {
+		out[key] = g.induced(nodes)
+	}
+	return out
+}
+
+// TrimLeaves returns a new Graph with a copy of the Graph's Nodes and edges, after peeling off the leaves
+// k times: each time, the Nodes without Next Nodes are removed, which may leave their parents as new leaves.
+// The original Graph is unchanged.
+func (g Graph) TrimLeaves(k int) Graph {
+	kept := make(map[*Node]bool, len(g))
+	for _, n := range g {
+		kept[n] = true
+	}
+	for i := 0; i < k; i++ {
+		leaves := []*Node{}
+		for n := range kept {
+			isLeaf := true
+			for _, next := range n.Next {
+				if kept[next] {
+					isLeaf = false
+					break
+				}
+			}
+			if isLeaf {
+				leaves = append(leaves, n)
+			}
+		}
+		for _, n := range leaves {
+			delete(kept, n)
+		}
+	}
+	return g.induced(kept)
+}
+
+// Normalize returns a new Graph in which structurally identical Nodes are merged into the one with the lowest ID.
+// Nodes are identical if their Evaluators are Named with the same name and they have the same parents and the
+// same Next Nodes, so they compute the same result; for example, duplicate constants feeding the same targets.
+// Each Next Node of a merged Node receives its input once instead of once per duplicate, so identical Nodes are
+// only merged if every Next Node has an Idempotent Evaluator, and the results of the Graph are preserved.
+// The returned Graph has not been evaluated, and the original Graph is unchanged.
+func (g Graph) Normalize() Graph {
+	// Merging a Node removes it from Nodes that are also connected to the Node it is merged into, so it
+	// can't make other Nodes identical, and a single pass finds every identical Node.
+	parents := g.parents()
+	kept := make(map[*Node]bool, len(g))
+	seen := make(map[string]bool)
+	for _, n := range g.SortedNodes() {
+		if named, ok := n.eval.(namedEvaluator); ok && idempotentNext(n) {
+			key := fmt.Sprintf("%q %q %q", named.name, sortedIDs(parents[n]), sortedIDs(n.Next))
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		kept[n] = true
+	}
+	out := g.induced(kept)
+	// Drop merged parents from the InputOrder of their Next Nodes.
+	for _, n := range out {
+		if n.InputOrder == nil {
+			continue
+		}
+		order := n.InputOrder[:0]
+		for _, id := range n.InputOrder {
+			if _, ok := out[id]; ok {
+				order = append(order, id)
+			}
+		}
+		n.InputOrder = order
+	}
+	return out
+}
+
+// idempotentNext reports whether every Next Node of the Node has an Idempotent Evaluator.
+func idempotentNext(n *Node) bool {
+	for _, next := range n.Next {
+		if !isIdempotent(next.eval) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedIDs returns the IDs of the Nodes, sorted.
+func sortedIDs(nodes []*Node) []string {
+	ids := nodeIDs(nodes)
+	sort.Strings(ids)
+	return ids
+}
+
+// induced returns a new Graph with a copy of each of the given Nodes and of the edges between them.
+// The returned Graph is not validated.
+func (g Graph) induced(nodes map[*Node]bool) Graph {
+	copies := make(map[*Node]*Node, len(nodes))
+	out := make(Graph, len(nodes))
+	for _, node := range g.SortedNodes() {
+		if nodes[node] {
+			copies[node] = node.copyWithoutEdges()
+			out[node.ID] = copies[node]
+		}
+	}
+	for _, node := range g.SortedNodes() {
+		if !nodes[node] {
+			continue
+		}
+		for _, next := range node.Next {
+			if nodes[next] {
+				copies[node].connect(copies[next])
+			}
+		}
+	}
+	return out
+}
+
+// ErrUnmappedEdge is returned by Replace when an edge between a replaced Node and a remaining Node
+// has no corresponding replacement Node.
+var ErrUnmappedEdge = errors.New("unmapped edge")
+
+// Replace returns a new Graph in which the Nodes with the given IDs are removed and the Nodes of
+// the replacement Graph are added. Edges from remaining Nodes into a removed Node are redirected to
+// the replacement Node that inbound maps the removed Node's ID to, and edges from a removed Node to
+// remaining Nodes are sent from the replacement Node that outbound maps the removed Node's ID to.
+// Edges between removed Nodes are dropped. The original and replacement Graphs are unchanged.
+// If an ID is not in the Graph it is expected in, ErrUnknownNode is returned.
+// If a replacement Node ID is already used by a remaining Node, ErrDuplicateID is returned.
+// If an edge to or from a removed Node has no mapping, ErrUnmappedEdge is returned.
+func (g Graph) Replace(oldIDs []string, replacement Graph, inbound map[string]string, outbound map[string]string) (Graph, error) {
+	removed := make(map[string]bool, len(oldIDs))
+	for _, id := range oldIDs {
+		if _, ok := g[id]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNode, id)
+		}
+		removed[id] = true
+	}
+	for _, mapping := range []map[string]string{inbound, outbound} {
+		for from, to := range mapping {
+			if !removed[from] {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownNode, from)
+			}
+			if _, ok := replacement[to]; !ok {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownNode, to)
+			}
+		}
+	}
+
+	// Copy the remaining Nodes and the replacement Nodes.
+	byID := make(map[string]*Node, len(g)+len(replacement))
+	for _, node := range g.SortedNodes() {
+		if !removed[node.ID] {
+			byID[node.ID] = node.copyWithoutEdges()
+		}
+	}
+	for _, node := range replacement.SortedNodes() {
+		if _, ok := byID[node.ID]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateID, node.ID)
+		}
+		byID[node.ID] = node.copyWithoutEdges()
+	}
+
+	// Collect the edges of the new Graph, redirecting those to and from removed Nodes.
+	edges := [][2]string{}
+	for _, node := range g.SortedNodes() {
+		for _, next := range node.Next {
+			from, to := node.ID, next.ID
+			switch {
+			case removed[from] && removed[to]:
+				continue
+			case removed[from]:
+				if from = outbound[from]; from == "" {
+					return nil, fmt.Errorf("%w: %s to %s", ErrUnmappedEdge, node.ID, next.ID)
+				}
+			case removed[to]:
+				if to = inbound[to]; to == "" {
+					return nil, fmt.Errorf("%w: %s to %s", ErrUnmappedEdge, node.ID, next.ID)
+				}
+			}
+			edges = append(edges, [2]string{from, to})
+		}
+	}
+	for _, node := range replacement.SortedNodes() {
+		for _, next := range node.Next {
+			edges = append(edges, [2]string{node.ID, next.ID})
+		}
+	}
+
+	// Connect each distinct edge once, since several removed Nodes may map to the same replacement Node.
+	connected := map[[2]string]bool{}
+	for _, edge := range edges {
+		if !connected[edge] {
+			connected[edge] = true
+			byID[edge[0]].connect(byID[edge[1]])
+		}
+	}
+
+	nodes := make([]*Node, 0, len(byID))
+	for _, node := range byID {
+		nodes = append(nodes, node)
+	}
+	return New(nodes...)
+}
@@ -0,0 +1,243 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRelabel(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	relabeled, err := graph.Relabel(map[string]string{"sum": "total"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := relabeled["sum"]; ok {
+		t.Fatal("expected node sum to be renamed")
+	}
+	if _, ok := graph["total"]; ok {
+		t.Fatal("expected the original graph to be unchanged")
+	}
+	if err := relabeled.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := relabeled["total"].Result; result != 5 {
+		t.Fatalf("unexpected result for node total: want 5 but got %d", result)
+	}
+
+	if _, err := graph.Relabel(map[string]string{"nope": "x"}); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+	if _, err := graph.Relabel(map[string]string{"min": "max"}); !errors.Is(err, ErrDuplicateID) {
+		t.Fatalf("expected ErrDuplicateID but got %v", err)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replace "sum" with two Nodes that compute the same total.
+	sumReplacement, err := New(NewNode("partial", Sum, NewNode("total", Sum)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaced, err := graph.Replace([]string{"sum"}, sumReplacement, map[string]string{"sum": "partial"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := replaced["sum"]; ok {
+		t.Fatal("expected node sum to be removed")
+	}
+	if err := replaced.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := replaced["total"].Result; result != 5 {
+		t.Fatalf("unexpected result for node total: want 5 but got %d", result)
+	}
+
+	// Replace "max", which has both inbound and outbound edges.
+	maxReplacement, err := New(NewNode("max1", Max, NewNode("max2", Max)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaced, err = graph.Replace([]string{"max"}, maxReplacement, map[string]string{"max": "max1"}, map[string]string{"max": "max2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := replaced.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := replaced["sum"].Result; result != 5 {
+		t.Fatalf("unexpected result for node sum: want 5 but got %d", result)
+	}
+
+	if _, err := graph.Replace([]string{"max"}, maxReplacement, map[string]string{"max": "max1"}, nil); !errors.Is(err, ErrUnmappedEdge) {
+		t.Fatalf("expected ErrUnmappedEdge but got %v", err)
+	}
+	if _, err := graph.Replace([]string{"nope"}, maxReplacement, nil, nil); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+	if _, err := graph.Replace([]string{"max"}, sumReplacement, map[string]string{"max": "max1"}, nil); !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode but got %v", err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	graph, err := assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := graph.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	clone := graph.Clone()
+	if err := clone.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	for id, node := range graph {
+		if clone[id] == node {
+			t.Fatalf("expected node %s to be copied", id)
+		}
+		if result := clone[id].Result; result != node.Result {
+			t.Fatalf("unexpected result for node %s: want %d but got %d", id, node.Result, result)
+		}
+	}
+}
+
+func TestPartitionByRoot(t *testing.T) {
+	graph, err := NewWithOptions(
+		[]*Node{
+			NewNode("a", Constant(1), NewNode("a1", Sum), NewNode("a2", Sum, NewNode("a3", Sum))),
+			NewNode("b", Constant(2), NewNode("b1", Sum)),
+		},
+		WithComponents([][]string{{"a"}, {"b"}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partitions := graph.PartitionByRoot()
+	if len(partitions) != 2 {
+		t.Fatalf("expected 2 partitions but got %d", len(partitions))
+	}
+	for root, ids := range map[string][]string{"a": {"a", "a1", "a2", "a3"}, "b": {"b", "b1"}} {
+		partition := partitions[root]
+		if len(partition) != len(ids) {
+			t.Fatalf("unexpected partition for root %s: want %v but got %v", root, ids, partition.Edges())
+		}
+		for _, id := range ids {
+			if _, ok := partition[id]; !ok {
+				t.Fatalf("expected node %s in the partition for root %s", id, root)
+			}
+		}
+		if err := partition.Evaluate(2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if result := partitions["a"]["a3"].Result; result != 1 {
+		t.Fatalf("unexpected result for node a3: want 1 but got %d", result)
+	}
+	if graph["a3"].Result != 0 {
+		t.Fatal("expected the original graph to be unchanged")
+	}
+
+	// Nodes reachable from several roots go to the shared partition.
+	graph, err = assignmentGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	partitions = graph.PartitionByRoot()
+	if len(partitions) != 5 {
+		t.Fatalf("expected 5 partitions but got %d", len(partitions))
+	}
+	shared := partitions[SharedPartition]
+	if len(shared) != 3 || shared["max"] == nil || shared["min"] == nil || shared["sum"] == nil {
+		t.Fatalf("unexpected shared partition: %v", shared.Edges())
+	}
+	if len(partitions["1"]) != 1 || len(partitions["1"]["1"].Next) != 0 {
+		t.Fatal("expected the partition for root 1 to hold only node 1 without edges")
+	}
+}
+
+func TestTrimLeaves(t *testing.T) {
+	graph, err := New(NewNode("1", Constant(1), NewNode("2", Sum, NewNode("3", Sum, NewNode("4", Sum, NewNode("5", Sum))))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trimmed := graph.TrimLeaves(2)
+	if ids := fmt.Sprint(nodeIDs(trimmed.SortedNodes())); ids != "[1 2 3]" {
+		t.Fatalf("unexpected nodes after trimming: want [1 2 3] but got %s", ids)
+	}
+	if len(trimmed["3"].Next) != 0 {
+		t.Fatal("expected node 3 to be a leaf after trimming")
+	}
+	if len(graph) != 5 || len(graph["3"].Next) != 1 {
+		t.Fatal("expected the original graph to be unchanged")
+	}
+	if err := trimmed.Evaluate(1); err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.TrimLeaves(5)) != 0 {
+		t.Fatal("expected trimming the whole chain to leave an empty graph")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	// "a" and "b" are the same constant feeding the same Node, while "c" is not Named.
+	max := NewEvaluatorNode("max", Idempotent(EvalFunc(Max)))
+	five := func() Evaluator { return Named("const:5", Constant(5)) }
+	graph, err := New(
+		NewEvaluatorNode("a", five(), max),
+		NewEvaluatorNode("b", five(), max),
+		NewNode("c", Constant(5), max))
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalized := graph.Normalize()
+	if ids := fmt.Sprint(nodeIDs(normalized.SortedNodes())); ids != "[a c max]" {
+		t.Fatalf("unexpected nodes after normalizing: got %s", ids)
+	}
+	if len(graph) != 4 {
+		t.Fatalf("expected the original graph to be unchanged but it has %d nodes", len(graph))
+	}
+	if err := normalized.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := normalized["max"].Result; result != 5 {
+		t.Fatalf("unexpected result for node max: want 5 but got %d", result)
+	}
+
+	// Named Nodes with different successors are not merged.
+	sum := NewNode("sum", Sum)
+	graph, err = New(
+		NewEvaluatorNode("a", five(), NewEvaluatorNode("x", Idempotent(EvalFunc(Max)), sum)),
+		NewEvaluatorNode("b", five(), NewEvaluatorNode("y", Idempotent(EvalFunc(Max)), sum)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if normalized := graph.Normalize(); len(normalized) != len(graph) {
+		t.Fatalf("expected no nodes to be merged but got %v", nodeIDs(normalized.SortedNodes()))
+	}
+
+	// Named Nodes feeding a Node that is not Idempotent are not merged, so the sum is unchanged.
+	sum = NewNode("sum", Sum)
+	graph, err = New(NewEvaluatorNode("a", five(), sum), NewEvaluatorNode("b", five(), sum))
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalized = graph.Normalize()
+	if len(normalized) != len(graph) {
+		t.Fatalf("expected no nodes to be merged but got %v", nodeIDs(normalized.SortedNodes()))
+	}
+	if err := normalized.Evaluate(2); err != nil {
+		t.Fatal(err)
+	}
+	if result := normalized["sum"].Result; result != 10 {
+		t.Fatalf("unexpected result for node sum: want 10 but got %d", result)
+	}
+}